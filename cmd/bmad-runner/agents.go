@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/MBFrosty/BMAD-Runner/internal/agent"
+	"github.com/MBFrosty/BMAD-Runner/internal/config"
+
+	"github.com/pterm/pterm"
+	"github.com/urfave/cli/v2"
+)
+
+// workflowPhases are the phases a phase agent can be mapped to. plan-epics and the
+// feature scout run as correct-course.
+var workflowPhases = []string{"create-story", "dev-story", "code-review", "retrospective", "correct-course"}
+
+// phaseAgents picks the runner and model for each phase. Phases mapped with
+// --phase-agent or phase_agents run on their own backend; the rest run on the
+// --agent-type runner.
+type phaseAgents struct {
+	agentType string // resolved --agent-type
+	model     string // --model; applies to phases that run on agentType
+	mapping   map[string]config.PhaseAgent
+	// envModels are the BMAD_RUNNER_MODEL_<PHASE> models when model came from a
+	// config file: the variables win over the file's model for their phases.
+	envModels map[string]config.ModelOverride
+	runners   map[string]*agent.Runner // by agent type
+}
+
+// newPhaseAgents builds the --agent-type runner with newRunner, plus one runner per
+// other backend the mapping uses for the given phases. Mapped backends are looked up
+// in PATH up front, so a missing binary fails before the first phase rather than
+// mid-story. Mappings for phases not listed are dropped.
+func newPhaseAgents(c *cli.Context, projectRoot string, phases ...string) (*phaseAgents, error) {
+	r, agentType, err := newRunner(c, projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	mapping, err := phaseAgentMapping(c)
+	if err != nil {
+		return nil, err
+	}
+	p := &phaseAgents{
+		agentType: agentType,
+		model:     c.String("model"),
+		mapping:   mapping,
+		runners:   map[string]*agent.Runner{agentType: r},
+	}
+	if _, fromFile := settingsOf(c).layers[config.KeyModel]; fromFile {
+		p.envModels = config.EnvModels()
+	}
+	for _, phase := range sortedKeys(mapping) {
+		m := mapping[phase]
+		if !slices.Contains(workflowPhases, phase) {
+			pterm.Warning.Printf("Phase agent for unknown phase %q is ignored (phases: %s)\n", phase, strings.Join(workflowPhases, ", "))
+			delete(p.mapping, phase)
+			continue
+		}
+		if !slices.Contains(phases, phase) {
+			delete(p.mapping, phase)
+			continue
+		}
+		if _, ok := p.runners[m.AgentType]; ok {
+			continue
+		}
+		if _, ok := agent.LookupBackend(m.AgentType); !ok {
+			return nil, fmt.Errorf("phase %s: unknown agent type %q (known: %s)", phase, m.AgentType, strings.Join(agent.BackendNames(), ", "))
+		}
+		agentPath, err := config.LookupAgent("", m.AgentType)
+		if err != nil {
+			return nil, fmt.Errorf("phase %s: looking up agent: %w", phase, err)
+		}
+		pr := *r
+		pr.AgentType = m.AgentType
+		pr.AgentPath = agentPath
+		p.runners[m.AgentType] = &pr
+	}
+	if c.Bool("pty") && !p.ptyCapable() {
+		pterm.Warning.Printf("--pty has no effect for %s\n", strings.Join(sortedKeys(p.runners), ", "))
+	}
+	return p, nil
+}
+
+// phaseAgentMapping merges the config files' phase_agents with --phase-agent
+// (or BMAD_RUNNER_PHASE_AGENT), which wins per phase.
+func phaseAgentMapping(c *cli.Context) (map[string]config.PhaseAgent, error) {
+	mapping := map[string]config.PhaseAgent{}
+	if res := settingsOf(c).resolver; res != nil {
+		for phase, o := range res.PhaseAgents() {
+			mapping[phase] = o.PhaseAgent
+		}
+	}
+	flags, err := config.ParsePhaseAgents(c.StringSlice("phase-agent"))
+	if err != nil {
+		return nil, err
+	}
+	for phase, p := range flags {
+		mapping[phase] = p
+	}
+	return mapping, nil
+}
+
+// forPhase returns the runner, agent type and model for phase. A model given in
+// the phase mapping wins; otherwise --model applies if the phase runs on the
+// --agent-type backend, and the backend's default model for the phase if not.
+// A --model filled from a config file gives way to the phase's
+// BMAD_RUNNER_MODEL_<PHASE> variable.
+func (p *phaseAgents) forPhase(phase string) (*agent.Runner, string, string) {
+	agentType, model := p.agentType, p.model
+	if o, ok := p.envModels[phase]; ok {
+		model = o.Model
+	}
+	if m, ok := p.mapping[phase]; ok {
+		if m.AgentType != agentType {
+			model = ""
+		}
+		agentType = m.AgentType
+		if m.Model != "" {
+			model = m.Model
+		}
+	}
+	if model == "" {
+		model = config.DefaultModel(agentType, phase)
+	}
+	return p.runners[agentType], agentType, model
+}
+
+// ptyCapable reports whether any backend in use has a PTY fallback for --pty.
+func (p *phaseAgents) ptyCapable() bool {
+	for agentType := range p.runners {
+		if agent.BackendFor(agentType).Capabilities().PTYFallback {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
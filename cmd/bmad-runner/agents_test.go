@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/MBFrosty/BMAD-Runner/internal/config"
+)
+
+func TestPhaseAgentsForPhaseModel(t *testing.T) {
+	t.Parallel()
+	env := map[string]config.ModelOverride{
+		"dev-story": {Model: "env-dev", Layer: config.Layer{Source: config.SourceEnv, Path: "BMAD_RUNNER_MODEL_DEV_STORY"}},
+	}
+	tests := []struct {
+		name      string
+		envModels map[string]config.ModelOverride
+		mapping   map[string]config.PhaseAgent
+		phase     string
+		want      string
+	}{
+		{"file model", nil, nil, "dev-story", "file-model"},
+		{"env beats file model", env, nil, "dev-story", "env-dev"},
+		{"file model for other phases", env, nil, "code-review", "file-model"},
+		{"mapping model beats env", env, map[string]config.PhaseAgent{"dev-story": {AgentType: config.AgentTypeClaudeCode, Model: "mapped"}}, "dev-story", "mapped"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			p := &phaseAgents{agentType: config.AgentTypeClaudeCode, model: "file-model", mapping: tt.mapping, envModels: tt.envModels}
+			if _, _, got := p.forPhase(tt.phase); got != tt.want {
+				t.Errorf("forPhase(%q) model = %q, want %q", tt.phase, got, tt.want)
+			}
+		})
+	}
+}
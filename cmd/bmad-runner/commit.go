@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/MBFrosty/BMAD-Runner/internal/gitops"
+	"github.com/MBFrosty/BMAD-Runner/internal/session"
+
+	"github.com/pterm/pterm"
+	"github.com/urfave/cli/v2"
+)
+
+// commitFlags are accepted by run and run auto.
+var commitFlags = []cli.Flag{
+	&cli.BoolFlag{
+		Name:    "commit",
+		EnvVars: []string{"BMAD_RUNNER_COMMIT"},
+		Usage:   "Commit all changes after each successful phase, with the story, phase, agent, model and duration in the message",
+	},
+	&cli.BoolFlag{
+		Name:    "allow-dirty",
+		EnvVars: []string{"BMAD_RUNNER_ALLOW_DIRTY"},
+		Usage:   "With --commit, start even if the working tree has uncommitted changes (they go into the first commit)",
+	},
+}
+
+// phaseCommitter commits the working tree after each successful phase (--commit).
+// A nil *phaseCommitter commits nothing.
+type phaseCommitter struct {
+	repo    *gitops.Repo
+	exclude []string // repo-relative runner files that are never committed
+}
+
+// newPhaseCommitter returns the committer for --commit, or nil without it. Unless
+// --allow-dirty (or --resume, whose leftovers belong to the interrupted story) is
+// given, it refuses a working tree with uncommitted changes, which would otherwise
+// end up in the first phase's commit.
+func newPhaseCommitter(c *cli.Context, projectRoot string) (*phaseCommitter, error) {
+	if !c.Bool("commit") {
+		return nil, nil
+	}
+	repo, err := gitops.Open(projectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("--commit: %w", err)
+	}
+	exclude, err := runnerFiles(repo, projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	pc := &phaseCommitter{repo: repo, exclude: exclude}
+
+	if c.Bool("allow-dirty") || c.Bool("resume") {
+		return pc, nil
+	}
+	changes, err := gitops.Changes(repo.Root, pc.exclude...)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) > 0 {
+		shown := changes
+		if len(shown) > 5 {
+			shown = append(shown[:5:5], fmt.Sprintf("... and %d more", len(changes)-5))
+		}
+		return nil, fmt.Errorf("--commit: the working tree has uncommitted changes; commit or stash them, or pass --allow-dirty:\n  %s", strings.Join(shown, "\n  "))
+	}
+	return pc, nil
+}
+
+// runnerFiles returns the repo-relative paths of the files the runner itself
+// writes under projectRoot, which are never committed or rolled back.
+func runnerFiles(repo *gitops.Repo, projectRoot string) ([]string, error) {
+	projectRel, err := repoRelative(repo, projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	return []string{
+		filepath.Join(projectRel, runnerLogsDir),
+		filepath.Join(projectRel, session.DefaultPath),
+	}, nil
+}
+
+// commit commits everything in the working tree dir (the main tree when "")
+// except the runner's own files and exclude (relative to dir) as one phase run.
+func (pc *phaseCommitter) commit(dir string, exclude []string, story, phase, agentType, model string, d time.Duration) error {
+	if pc == nil {
+		return nil
+	}
+	if dir == "" {
+		dir = pc.repo.Root
+	}
+	msg := phaseCommitMessage(story, phase, agentType, model, d)
+	committed, err := gitops.CommitAll(dir, msg, append(exclude, pc.exclude...)...)
+	if err != nil {
+		return fmt.Errorf("committing %s of %s: %w", phase, story, err)
+	}
+	if committed {
+		pterm.Success.Printf("Committed %s of %s\n", phase, story)
+	} else {
+		pterm.Info.Printf("Nothing to commit after %s of %s\n", phase, story)
+	}
+	return nil
+}
+
+// commitWorktree commits a phase run in the story worktree wt: the phase's
+// changes on the story branch, and the sprint-status.yaml changes reconciled into
+// the main tree as a commit of their own there, through wt's writer, so the main
+// tree is not left dirty.
+func (pc *phaseCommitter) commitWorktree(wt *storyWorktree, story, phase, agentType, model string, d time.Duration) error {
+	if pc == nil {
+		return nil
+	}
+	if err := pc.commit(wt.dir, []string{wt.statusRel}, story, phase, agentType, model, d); err != nil {
+		return err
+	}
+	subject, body, _ := strings.Cut(phaseCommitMessage(story, phase, agentType, model, d), "\n")
+	msg := subject + " (sprint status)\n" + body
+	var committed bool
+	err := wt.writer.do(func() error {
+		var err error
+		committed, err = gitops.CommitPaths(pc.repo.Root, msg, wt.statusRel)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("committing sprint status after %s of %s: %w", phase, story, err)
+	}
+	if committed {
+		pterm.Success.Printf("Committed sprint status after %s of %s\n", phase, story)
+	}
+	return nil
+}
+
+// phaseCommitMessage formats the commit message for a phase run: a subject naming
+// the story and phase, a summary line, and BMAD-* trailers for tooling.
+func phaseCommitMessage(story, phase, agentType, model string, d time.Duration) string {
+	d = d.Round(time.Second)
+	var b strings.Builder
+	fmt.Fprintf(&b, "bmad(%s): %s\n\n", story, phase)
+	fmt.Fprintf(&b, "%s for %s by %s (%s) in %s.\n\n", phase, story, agentType, model, d)
+	fmt.Fprintf(&b, "BMAD-Story: %s\n", story)
+	fmt.Fprintf(&b, "BMAD-Phase: %s\n", phase)
+	fmt.Fprintf(&b, "BMAD-Agent: %s\n", agentType)
+	fmt.Fprintf(&b, "BMAD-Model: %s\n", model)
+	fmt.Fprintf(&b, "BMAD-Duration: %s\n", d)
+	return b.String()
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/MBFrosty/BMAD-Runner/internal/status"
+	"github.com/MBFrosty/BMAD-Runner/internal/ui"
+
+	"github.com/pterm/pterm"
+)
+
+// printAutoPlan is `run auto --dry-run`: it prints every story/phase invocation the
+// auto loop would make, with agent and model, assuming each phase succeeds and
+// updates sprint-status.yaml. No agent is started.
+func printAutoPlan(agents *phaseAgents, statusPath string, maxIter int, epicPlanning bool) error {
+	s, err := status.Parse(statusPath)
+	if err != nil {
+		return fmt.Errorf("parsing status file: %w", err)
+	}
+	steps := s.Plan()
+
+	pterm.DefaultSection.Println("Dry run — run auto plan")
+	pterm.Info.Printf("Status: %s\n", statusPath)
+	pterm.Info.Println("Assumes every phase succeeds and updates sprint-status.yaml; no agent is started.")
+	pterm.Println()
+
+	tableData := pterm.TableData{
+		{"#", "Epic", "Work", "Status", "Phase", "Agent", "Model"},
+	}
+	phaseRuns := 0
+	for i, step := range steps {
+		for j, phase := range step.Phases {
+			_, agentType, model := agents.forPhase(phase)
+			row := []string{"", "", "", "", phase, agentType, model}
+			if j == 0 {
+				row[0], row[1], row[2], row[3] = strconv.Itoa(i+1), step.Epic, step.Key, ui.StatusIcon(step.From)
+			}
+			if i >= maxIter {
+				for k := range row {
+					row[k] = pterm.Gray(row[k])
+				}
+			}
+			tableData = append(tableData, row)
+			phaseRuns++
+		}
+	}
+
+	if len(steps) == 0 {
+		pterm.Success.Println("No work pending — run auto would stop immediately")
+	} else {
+		pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+		pterm.Println()
+		pterm.Info.Printf("%d iterations, %d phase runs (--max-iterations %d)\n", len(steps), phaseRuns, maxIter)
+	}
+	if len(steps) > maxIter {
+		pterm.Warning.Printf("run auto would stop after %d iterations with %d work items left (grayed out); use --max-iterations %d to finish\n", maxIter, len(steps)-maxIter, len(steps))
+	}
+	if epicPlanning && len(steps) < maxIter {
+		_, agentType, model := agents.forPhase("correct-course")
+		pterm.Info.Printf("Then epic planning: correct-course on %s (%s); planned epics are not simulated\n", agentType, model)
+	}
+	return nil
+}
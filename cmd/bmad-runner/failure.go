@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/MBFrosty/BMAD-Runner/internal/agent"
+	"github.com/MBFrosty/BMAD-Runner/internal/gitops"
+	"github.com/MBFrosty/BMAD-Runner/internal/status"
+
+	"github.com/pterm/pterm"
+)
+
+// run auto --on-failure actions for a story whose phase fails.
+const (
+	onFailureKeep     = "keep"     // leave the agent's changes for inspection
+	onFailureRollback = "rollback" // restore the state the story started from
+	onFailureBranch   = "branch"   // park the changes on a branch, then roll back
+)
+
+// failedBranchPrefix prefixes the branches failed stories are parked on.
+const failedBranchPrefix = "bmad-failed/"
+
+// openFailureRepo checks the --on-failure mode and returns the repository holding
+// projectRoot, or nil for keep, which needs no git.
+func openFailureRepo(mode, projectRoot string) (*gitops.Repo, error) {
+	switch mode {
+	case onFailureKeep:
+		return nil, nil
+	case onFailureRollback, onFailureBranch:
+		repo, err := gitops.Open(projectRoot)
+		if err != nil {
+			return nil, fmt.Errorf("--on-failure %s: %w", mode, err)
+		}
+		return repo, nil
+	default:
+		return nil, fmt.Errorf("--on-failure %s: want %s, %s or %s", mode, onFailureRollback, onFailureKeep, onFailureBranch)
+	}
+}
+
+// storySnapshot is the state a story's pipeline started from, taken so that
+// --on-failure can put it back if the story fails.
+type storySnapshot struct {
+	repo    *gitops.Repo // nil for keep
+	mode    string
+	story   string
+	exclude []string // runner files, left alone by rollback
+
+	head   string // commit checked out when the story started
+	commit string // working tree when the story started; "" with --worktree
+
+	statusPath string
+	status     []byte       // sprint-status.yaml when the story started; nil with --worktree
+	storyState status.State // the story's entry when it started, restored with --worktree
+}
+
+// newStorySnapshot records the state before story runs. With inWorktree the story's
+// work is on its branch and the main tree only needs the story's sprint-status
+// entry back; other stories may be updating the file meanwhile (--parallel).
+func newStorySnapshot(repo *gitops.Repo, mode, projectRoot, statusPath, story string, inWorktree bool) (*storySnapshot, error) {
+	snap := &storySnapshot{repo: repo, mode: mode, story: story, statusPath: statusPath}
+	if repo == nil {
+		return snap, nil
+	}
+	if inWorktree {
+		s, err := status.Parse(statusPath)
+		if err != nil {
+			return nil, fmt.Errorf("parsing status file: %w", err)
+		}
+		snap.storyState = s.DevStatus[story]
+		return snap, nil
+	}
+	var err error
+	if snap.status, err = os.ReadFile(statusPath); err != nil {
+		return nil, fmt.Errorf("reading status file: %w", err)
+	}
+	if snap.exclude, err = runnerFiles(repo, projectRoot); err != nil {
+		return nil, err
+	}
+	if snap.head, err = repo.Head(); err != nil {
+		return nil, fmt.Errorf("snapshot before %s: %w", story, err)
+	}
+	if snap.commit, err = repo.Snapshot("bmad-runner: before "+story, snap.exclude...); err != nil {
+		return nil, fmt.Errorf("snapshot before %s: %w", story, err)
+	}
+	return snap, nil
+}
+
+// fail applies the --on-failure action after the story failed with cause, prints
+// it and records it in report. wt is the story's worktree, or nil. It reports
+// whether the story's work is gone from the working tree, so that the session
+// no longer has a story to resume.
+func (s *storySnapshot) fail(cause error, wt *storyWorktree, report *agent.Report) (undone bool) {
+	action, err := s.undo(cause, wt)
+	if err != nil {
+		action = fmt.Sprintf("%s of story %s failed: %v", s.mode, s.story, err)
+		pterm.Error.Println(action)
+	} else if s.mode == onFailureKeep {
+		pterm.Info.Println(action)
+	} else {
+		pterm.Warning.Println(action)
+	}
+	report.SetFailureAction(action)
+	return err == nil && s.mode != onFailureKeep
+}
+
+// undo carries out the --on-failure action and describes what it did.
+func (s *storySnapshot) undo(cause error, wt *storyWorktree) (string, error) {
+	if s.mode == onFailureKeep {
+		where := "the working tree"
+		if wt != nil {
+			where = wt.dir
+		}
+		return fmt.Sprintf("Kept the changes of failed story %s in %s for inspection", s.story, where), nil
+	}
+
+	var parked string
+	if s.mode == onFailureBranch {
+		parked = fmt.Sprintf("%s%s-%s", failedBranchPrefix, s.story, time.Now().Format("20060102-150405"))
+		msg := fmt.Sprintf("bmad-runner: failed story %s\n\n%v", s.story, cause)
+		if err := s.park(wt, parked, msg); err != nil {
+			return "", err
+		}
+	}
+
+	var err error
+	if wt != nil {
+		err = wt.writer.do(func() error {
+			if err := s.repo.RemoveWorktree(wt.dir); err != nil {
+				return err
+			}
+			if parked != "" {
+				err = s.repo.RenameBranch(wt.branch, parked)
+			} else {
+				err = s.repo.DiscardBranch(wt.branch)
+			}
+			if err != nil {
+				return err
+			}
+			return status.SetStatus(s.statusPath, s.story, s.storyState)
+		})
+	} else if err = s.repo.Restore(s.head, s.commit, s.exclude...); err == nil {
+		// The status file may be untracked, ignored or outside the repository.
+		err = status.WriteFileAtomic(s.statusPath, s.status)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if parked != "" {
+		return fmt.Sprintf("Parked the changes of failed story %s on branch %s and rolled back", s.story, parked), nil
+	}
+	return fmt.Sprintf("Rolled back failed story %s to the state it started from", s.story), nil
+}
+
+// park saves the failed story's work, including any phase commits, on branch.
+// A worktree's work is committed on the story branch, which undo then renames.
+func (s *storySnapshot) park(wt *storyWorktree, branch, msg string) error {
+	if wt != nil {
+		_, err := gitops.CommitAll(wt.dir, msg)
+		return err
+	}
+	commit, err := s.repo.Snapshot(msg, s.exclude...)
+	if err != nil {
+		return err
+	}
+	return s.repo.CreateBranch(branch, commit)
+}
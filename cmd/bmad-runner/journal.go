@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/MBFrosty/BMAD-Runner/internal/session"
+
+	"github.com/pterm/pterm"
+	"github.com/urfave/cli/v2"
+)
+
+// openJournal returns the checkpoint journal for a run auto session: with --resume
+// the interrupted session's journal from path, otherwise a new one. A new session
+// mentions an interrupted one it replaces.
+func openJournal(c *cli.Context, path, statusPath string) (*session.Journal, error) {
+	prev, err := session.Load(path)
+	if !c.Bool("resume") {
+		if err != nil {
+			pterm.Warning.Printf("Ignoring unreadable session journal: %v\n", err)
+		} else {
+			for _, w := range prev.InterruptedStories() {
+				pterm.Warning.Printf("A previous session stopped during %s of story %s; starting a new session (use --resume to continue from that phase)\n", w.Remaining()[0], w.Story)
+			}
+		}
+		return session.New(statusPath), nil
+	}
+
+	switch {
+	case err != nil:
+		return nil, err
+	case prev == nil:
+		pterm.Warning.Printf("No session to resume (%s not found); starting a new session\n", path)
+		return session.New(statusPath), nil
+	case prev.StatusFile != statusPath:
+		return nil, fmt.Errorf("session journal %s is for %s, not %s", path, prev.StatusFile, statusPath)
+	}
+	pterm.Info.Printf("Resuming session started %s: iteration %d, %d epic(s) planned\n", prev.Started.Local().Format("2006-01-02 15:04"), prev.Iteration+1, prev.EpicPlanningCount)
+	return prev, nil
+}
+
+// saveJournal checkpoints j to path. A failed write is reported but does not stop
+// the session.
+func saveJournal(j *session.Journal, path string) {
+	if err := j.Save(path); err != nil {
+		pterm.Warning.Printf("Session journal not saved: %v\n", err)
+	}
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/MBFrosty/BMAD-Runner/internal/agent"
+	"github.com/MBFrosty/BMAD-Runner/internal/config"
+	"github.com/MBFrosty/BMAD-Runner/internal/ui"
+
+	"github.com/pterm/pterm"
+	"github.com/urfave/cli/v2"
+)
+
+// followPoll is how often logs --follow checks for new output and new phases.
+const followPoll = 250 * time.Millisecond
+
+// followIdle is how long logs --follow waits on a silent transcript that names no
+// runner process before moving on to a newer one.
+const followIdle = time.Minute
+
+// logsDir resolves the transcript directory for the project selected by c's flags.
+func logsDir(c *cli.Context) (string, error) {
+	projectRoot, _, err := config.ResolveProjectRoot(c.String("status-file"), c.String("project-root"))
+	if err != nil {
+		return "", fmt.Errorf("resolving project root: %w", err)
+	}
+	return filepath.Join(projectRoot, runnerLogsDir), nil
+}
+
+// runLogsList lists past phase runs, newest first, or follows the running phase with --follow.
+func runLogsList(c *cli.Context) error {
+	dir, err := logsDir(c)
+	if err != nil {
+		return err
+	}
+	if c.Bool("follow") {
+		return followLogs(c.Context, dir, c.String("story"), c.Bool("raw"))
+	}
+
+	runs, err := agent.ListTranscripts(dir)
+	if err != nil {
+		return fmt.Errorf("listing transcripts: %w", err)
+	}
+	if len(runs) == 0 {
+		pterm.Info.Printf("No phase transcripts in %s\n", dir)
+		return nil
+	}
+
+	tableData := pterm.TableData{
+		{"#", "Started", "Story", "Phase", "Agent", "Model", "Duration", "Result"},
+	}
+	shown := 0
+	for i, run := range runs {
+		if story := c.String("story"); story != "" && run.Start.Story != story {
+			continue
+		}
+		if limit := c.Int("limit"); limit > 0 && shown >= limit {
+			break
+		}
+		shown++
+		tableData = append(tableData, []string{
+			strconv.Itoa(i + 1),
+			run.Start.Time.Local().Format("2006-01-02 15:04"),
+			run.Start.Story,
+			run.Start.Phase,
+			run.Start.Agent,
+			run.Start.Model,
+			run.Duration().Round(time.Second).String(),
+			ui.ResultIcon(run.Result()),
+		})
+	}
+	pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+	pterm.Info.Println("Open a run with: bmad-runner logs show <#|story-key>")
+	return nil
+}
+
+// runLogsShow prints one transcript, selected by list index or story key.
+func runLogsShow(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf("usage: bmad-runner logs show [flags] <#|story-key> (flags go before the argument)")
+	}
+	dir, err := logsDir(c)
+	if err != nil {
+		return err
+	}
+	runs, err := agent.ListTranscripts(dir)
+	if err != nil {
+		return fmt.Errorf("listing transcripts: %w", err)
+	}
+	run, err := selectRun(runs, c.Args().First(), c.String("phase"))
+	if err != nil {
+		return err
+	}
+
+	printRunHeader(run.Start, run.Path)
+	if c.Bool("prompt") {
+		pterm.DefaultSection.Println("Prompt")
+		pterm.Println(run.Start.Prompt)
+		pterm.DefaultSection.Println("Output")
+	}
+	records, err := agent.ReadTranscript(run.Path)
+	if err != nil {
+		return err
+	}
+	formatter := agent.NewRecordFormatter(run.Start.Agent)
+	for _, rec := range records {
+		printRecord(rec, formatter, c.Bool("raw"), "")
+	}
+	if run.End == nil {
+		pterm.Info.Println("Phase has not finished (still running, or the runner was killed)")
+	}
+	return nil
+}
+
+// selectRun picks a run by 1-based index into runs (newest first), or the newest
+// run for a story key, optionally restricted to one phase.
+func selectRun(runs []agent.TranscriptSummary, ref, phase string) (agent.TranscriptSummary, error) {
+	if n, err := strconv.Atoi(ref); err == nil {
+		if n < 1 || n > len(runs) {
+			return agent.TranscriptSummary{}, fmt.Errorf("no run #%d (%d runs recorded)", n, len(runs))
+		}
+		return runs[n-1], nil
+	}
+	for _, run := range runs {
+		if run.Start.Story == ref && (phase == "" || run.Start.Phase == phase) {
+			return run, nil
+		}
+	}
+	if phase != "" {
+		return agent.TranscriptSummary{}, fmt.Errorf("no %s run recorded for %s", phase, ref)
+	}
+	return agent.TranscriptSummary{}, fmt.Errorf("no runs recorded for %s", ref)
+}
+
+// followLogs tails every running transcript, and each one that starts later, until
+// ctx is cancelled. With story set only that story's phases are followed. Lines
+// are prefixed with their story, since --parallel runs several phases at once.
+func followLogs(ctx context.Context, dir, story string, raw bool) error {
+	pterm.Info.Println("Following the running phases and the ones that start next... (Ctrl-C to stop)")
+	formatters := map[string]*agent.RecordFormatter{}
+	labels := map[string]string{}
+	keep := func(run agent.TranscriptSummary) bool { return story == "" || run.Start.Story == story }
+	err := agent.FollowTranscripts(ctx, dir, followPoll, followIdle, keep, func(ev agent.FollowEvent) {
+		switch {
+		case errors.Is(ev.Err, agent.ErrTranscriptAbandoned):
+			pterm.Warning.Printf("%sThe runner stopped without finishing this phase\n", labels[ev.Path])
+		case ev.Err != nil:
+			pterm.Warning.Printf("Following %s: %v\n", ev.Path, ev.Err)
+		case ev.Record.Type == agent.RecordStart:
+			formatters[ev.Path] = agent.NewRecordFormatter(ev.Record.Agent)
+			labels[ev.Path] = followLabel(ev.Record)
+			printRunHeader(ev.Record, ev.Path)
+		default:
+			printRecord(ev.Record, formatters[ev.Path], raw, labels[ev.Path])
+		}
+	})
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("listing transcripts: %w", err)
+	}
+	return nil
+}
+
+// followLabel is the prefix of a followed transcript's lines: its story, or its
+// phase if it has none.
+func followLabel(start agent.TranscriptRecord) string {
+	label := start.Story
+	if label == "" {
+		label = start.Phase
+	}
+	return pterm.Cyan("["+label+"]") + " "
+}
+
+func printRunHeader(start agent.TranscriptRecord, path string) {
+	title := start.Phase
+	if start.Story != "" {
+		title = start.Story + " · " + start.Phase
+	}
+	pterm.DefaultHeader.WithFullWidth().Println(title)
+	pterm.Info.Printf("Agent: %s  Model: %s\n", start.Agent, start.Model)
+	pterm.Info.Printf("Started: %s\n", start.Time.Local().Format("2006-01-02 15:04:05"))
+	pterm.Info.Printf("Transcript: %s\n\n", path)
+}
+
+// printRecord prints an output record formatted like the live display, or an end
+// record as a result line, each line after prefix. With raw, output records are
+// printed verbatim.
+func printRecord(rec agent.TranscriptRecord, formatter *agent.RecordFormatter, raw bool, prefix string) {
+	stamp := pterm.Gray(rec.Time.Local().Format("15:04:05"))
+	switch rec.Type {
+	case agent.RecordOutput:
+		if raw {
+			line := rec.Line
+			if rec.Event != nil {
+				line = string(rec.Event)
+			}
+			pterm.Printf("%s%s %s\n", prefix, stamp, line)
+			return
+		}
+		for _, line := range formatter.Format(rec) {
+			if rec.Stream == "stderr" {
+				line = pterm.Red(line)
+			}
+			pterm.Printf("%s%s %s\n", prefix, stamp, line)
+		}
+	case agent.RecordEnd:
+		if prefix == "" {
+			pterm.Println()
+		}
+		msg := prefix + fmt.Sprintf("%s after %s", ui.ResultIcon(rec.Result),
+			(time.Duration(rec.DurationMS)*time.Millisecond).Round(time.Second))
+		if rec.Failure != "" && rec.Result == agent.ResultFailed {
+			msg += fmt.Sprintf(" (%s)", rec.Failure)
+		}
+		pterm.Println(msg)
+		if rec.Error != "" {
+			pterm.Println(prefix + pterm.Gray(rec.Error))
+		}
+	}
+}
@@ -3,14 +3,20 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/MBFrosty/BMAD-Runner/internal/agent"
 	"github.com/MBFrosty/BMAD-Runner/internal/config"
 	"github.com/MBFrosty/BMAD-Runner/internal/planner"
+	"github.com/MBFrosty/BMAD-Runner/internal/session"
 	"github.com/MBFrosty/BMAD-Runner/internal/status"
 	"github.com/MBFrosty/BMAD-Runner/internal/ui"
 
@@ -24,33 +30,122 @@ func main() {
 		&cli.StringFlag{
 			Name:    "status-file",
 			Aliases: []string{"s"},
+			EnvVars: []string{"BMAD_RUNNER_STATUS_FILE"},
 			Usage:   "Path to sprint-status.yaml",
 			Value:   "_bmad-output/implementation-artifacts/sprint-status.yaml",
 		},
 		&cli.StringFlag{
 			Name:    "agent-path",
 			Aliases: []string{"a"},
+			EnvVars: []string{"BMAD_RUNNER_AGENT_PATH"},
 			Usage:   "Path to agent binary (lookup in PATH by default)",
 		},
 		&cli.StringFlag{
 			Name:    "agent-type",
 			Aliases: []string{"t"},
-			Usage:   "Agent backend: cursor-agent, claude-code, gemini-cli, or opencode",
-			Value:   "cursor-agent",
+			EnvVars: []string{"BMAD_RUNNER_AGENT_TYPE"},
+			Usage:   "Agent backend: " + strings.Join(agent.BackendNames(), ", "),
+			Value:   agent.DefaultBackend,
 		},
 		&cli.StringFlag{
 			Name:    "project-root",
 			Aliases: []string{"r"},
+			EnvVars: []string{"BMAD_RUNNER_PROJECT_ROOT"},
 			Usage:   "Override project root (default: derived from status-file parent)",
 		},
 		&cli.StringFlag{
 			Name:    "model",
 			Aliases: []string{"m"},
+			EnvVars: []string{"BMAD_RUNNER_MODEL"},
 			Usage:   "Model to use (default: composer-1.5 for cursor-agent, sonnet for claude-code)",
 		},
+		&cli.StringSliceFlag{
+			Name:    "phase-agent",
+			EnvVars: []string{"BMAD_RUNNER_PHASE_AGENT"},
+			Usage:   "Run a phase on another agent backend as phase=agent[:model], repeatable (e.g. code-review=gemini-cli:gemini-3-pro)",
+		},
+		&cli.BoolFlag{
+			Name:    "no-live-status",
+			EnvVars: []string{"BMAD_RUNNER_NO_LIVE_STATUS"},
+			Usage:   "Disable last-lines display in spinner (e.g. for CI/scripts)",
+		},
+		&cli.DurationFlag{
+			Name:    "shutdown-grace",
+			EnvVars: []string{"BMAD_RUNNER_SHUTDOWN_GRACE"},
+			Usage:   "On Ctrl-C/SIGTERM, how long the agent may take to exit before it is killed",
+			Value:   agent.DefaultShutdownGrace,
+		},
+		&cli.StringSliceFlag{
+			Name:    "phase-timeout",
+			EnvVars: []string{"BMAD_RUNNER_PHASE_TIMEOUT"},
+			Usage:   "Wall-clock limit per phase as phase=duration, repeatable (e.g. dev-story=90m); a bare duration applies to all phases",
+		},
+		&cli.DurationFlag{
+			Name:    "idle-timeout",
+			EnvVars: []string{"BMAD_RUNNER_IDLE_TIMEOUT"},
+			Usage:   "Kill the agent if it produces no output for this long, e.g. when stuck on an interactive prompt (0 disables)",
+		},
+		&cli.BoolFlag{
+			Name:    "no-transcripts",
+			EnvVars: []string{"BMAD_RUNNER_NO_TRANSCRIPTS"},
+			Usage:   "Do not write per-phase agent transcripts to _bmad-output/runner-logs",
+		},
 		&cli.BoolFlag{
-			Name:  "no-live-status",
-			Usage: "Disable last-lines display in spinner (e.g. for CI/scripts)",
+			Name:    "pty",
+			EnvVars: []string{"BMAD_RUNNER_PTY"},
+			Usage:   "gemini-cli only: run the agent in a pseudo-terminal and show its text output instead of --output-format stream-json (for older gemini-cli versions)",
+		},
+	}
+
+	autoFlags := []cli.Flag{
+		&cli.IntFlag{
+			Name:    "max-iterations",
+			EnvVars: []string{"BMAD_RUNNER_MAX_ITERATIONS"},
+			Usage:   "Maximum loop iterations (safety limit)",
+			Value:   50,
+		},
+		&cli.BoolFlag{
+			Name:    "no-pause-after-retro",
+			EnvVars: []string{"BMAD_RUNNER_NO_PAUSE_AFTER_RETRO"},
+			Usage:   "Do not prompt after retrospective; continue immediately (for scripts/CI)",
+		},
+		&cli.BoolFlag{
+			Name:    "ignore-stall",
+			EnvVars: []string{"BMAD_RUNNER_IGNORE_STALL"},
+			Usage:   "Continue even when sprint-status.yaml is unchanged after a story (avoids exit on workflow sync failures)",
+		},
+		&cli.BoolFlag{
+			Name:    "enable-epic-planning",
+			EnvVars: []string{"BMAD_RUNNER_ENABLE_EPIC_PLANNING"},
+			Usage:   "When all stories are done, automatically plan the next epic via BMAD create-epics-and-stories + sprint-planning",
+		},
+		&cli.StringFlag{
+			Name:    "prime-directive",
+			EnvVars: []string{"BMAD_RUNNER_PRIME_DIRECTIVE"},
+			Usage:   "Path to the prime directive file that guides epic planning (default: <project-root>/_bmad-output/prime-directive.md)",
+		},
+		&cli.IntFlag{
+			Name:    "max-new-epics",
+			EnvVars: []string{"BMAD_RUNNER_MAX_NEW_EPICS"},
+			Usage:   "Maximum number of new epics to plan across this auto session (one per no-work event)",
+			Value:   planner.DefaultMaxEpics,
+		},
+		&cli.IntFlag{
+			Name:    "max-retries",
+			EnvVars: []string{"BMAD_RUNNER_MAX_RETRIES"},
+			Usage:   "Retries for a phase that fails transiently (rate limit, overload, network error, crash)",
+			Value:   2,
+		},
+		&cli.DurationFlag{
+			Name:    "retry-backoff",
+			EnvVars: []string{"BMAD_RUNNER_RETRY_BACKOFF"},
+			Usage:   "Delay before the first retry; doubles on each further retry",
+			Value:   30 * time.Second,
+		},
+		&cli.BoolFlag{
+			Name:    "model-fallback",
+			EnvVars: []string{"BMAD_RUNNER_MODEL_FALLBACK"},
+			Usage:   "After retries are used up, make one last attempt with the agent's fallback model",
 		},
 	}
 
@@ -90,141 +185,244 @@ func main() {
 			},
 			{
 				Name:  "status",
-				Usage: "Show current sprint status from YAML",
-				Flags: commonFlags,
-				Action: func(c *cli.Context) error {
-					_, statusPath, err := config.ResolveProjectRoot(c.String("status-file"), c.String("project-root"))
-					if err != nil {
-						return fmt.Errorf("resolving project root: %w", err)
-					}
-
-					s, err := status.Parse(statusPath)
-					if err != nil {
-						return fmt.Errorf("parsing status file: %w", err)
-					}
-
-					pterm.DefaultHeader.WithFullWidth().Println("Sprint Status: " + s.Project)
-					pterm.Info.Printf("File: %s\n", statusPath)
-					pterm.Info.Printf("Generated: %s\n\n", s.Generated)
-
-					tableData := pterm.TableData{
-						{"Type", "Key", "Status"},
-					}
-
-					for k, v := range s.DevStatus {
-						t := "Story"
-						if strings.HasPrefix(k, "epic-") {
-							t = "Epic"
-						}
-						tableData = append(tableData, []string{t, k, ui.StatusIcon(v)})
-					}
-
-					pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
-					return nil
+				Usage: "Show current sprint status from YAML, grouped by epic in file order",
+				Flags: append(commonFlags,
+					&cli.StringSliceFlag{
+						Name:  "epic",
+						Usage: "Only show these epics, by number or key (repeatable, e.g. --epic 3)",
+					},
+					&cli.StringSliceFlag{
+						Name:  "state",
+						Usage: "Only show entries with these statuses (repeatable, e.g. --state in-progress)",
+					},
+					&cli.BoolFlag{
+						Name:  "pending",
+						Usage: "Only show entries that are not done or deferred",
+					},
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "Output format: table, json or yaml",
+						Value:   "table",
+					},
+				),
+				Action: runStatus,
+			},
+			{
+				Name:  "validate",
+				Usage: "Check sprint-status.yaml for unknown states, misplaced stories and other problems (non-zero exit on errors)",
+				Flags: append(commonFlags,
+					&cli.BoolFlag{
+						Name:  "strict",
+						Usage: "Fail on warnings too",
+					},
+				),
+				Action: runValidate,
+			},
+			{
+				Name:      "set",
+				Usage:     "Set the status of a story, epic or retrospective in sprint-status.yaml",
+				ArgsUsage: "[flags] <key> <status>",
+				Flags: append(commonFlags,
+					&cli.BoolFlag{
+						Name:  "force",
+						Usage: "Allow a status that is not a known BMAD status for the key",
+					},
+				),
+				Action: runSet,
+			},
+			{
+				Name:      "defer",
+				Usage:     "Mark a story deferred so the auto loop skips it",
+				ArgsUsage: "[flags] <story-key>",
+				Flags:     commonFlags,
+				Action:    statusShortcut("defer", deferTarget),
+			},
+			{
+				Name:      "reset",
+				Usage:     "Move a story or epic back to backlog (a retrospective back to optional)",
+				ArgsUsage: "[flags] <key>",
+				Flags:     commonFlags,
+				Action:    statusShortcut("reset", resetTarget),
+			},
+			{
+				Name:      "reopen",
+				Usage:     "Move a done or deferred story or epic back to in-progress",
+				ArgsUsage: "[flags] <key>",
+				Flags:     commonFlags,
+				Action:    statusShortcut("reopen", reopenTarget),
+			},
+			{
+				Name:  "logs",
+				Usage: "List past phase runs from _bmad-output/runner-logs, or tail the running phase with --follow",
+				Flags: append(commonFlags,
+					&cli.StringFlag{
+						Name:  "story",
+						Usage: "Only list runs for this story key (with --follow, only follow it)",
+					},
+					&cli.IntFlag{
+						Name:    "limit",
+						Aliases: []string{"n"},
+						Usage:   "Maximum number of runs to list (0 = all)",
+						Value:   20,
+					},
+					&cli.BoolFlag{
+						Name:    "follow",
+						Aliases: []string{"f"},
+						Usage:   "Tail the running phases (and the phases after them) until Ctrl-C",
+					},
+					&cli.BoolFlag{
+						Name:  "raw",
+						Usage: "With --follow, print agent output lines verbatim instead of formatted",
+					},
+				),
+				Action: runLogsList,
+				Subcommands: []*cli.Command{
+					{
+						Name:      "show",
+						Usage:     "Print one phase run, by list number or story key (newest run for that story)",
+						ArgsUsage: "[flags] <#|story-key>",
+						Flags: append(commonFlags,
+							&cli.StringFlag{
+								Name:  "phase",
+								Usage: "With a story key, show this phase's newest run",
+							},
+							&cli.BoolFlag{
+								Name:  "prompt",
+								Usage: "Also print the prompt sent to the agent",
+							},
+							&cli.BoolFlag{
+								Name:  "raw",
+								Usage: "Print agent output lines verbatim instead of formatted",
+							},
+						),
+						Action: runLogsShow,
+					},
+				},
+			},
+			{
+				Name:  "config",
+				Usage: "Inspect runner configuration (.bmad-runner.yaml and the user config file)",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "show",
+						Usage:  "Print the effective settings and where each value comes from",
+						Flags:  append(commonFlags, autoFlags...),
+						Action: runConfigShow,
+					},
 				},
 			},
 			{
 				Name:  "run",
 				Usage: "Run BMAD workflow phases",
-				Flags: commonFlags,
+				Flags: append(append(commonFlags, commitFlags...), reportFlag),
 				Subcommands: []*cli.Command{
 					{
 						Name:  "create-story",
 						Usage: "Run create-story phase",
-						Flags: commonFlags,
-						Action: func(c *cli.Context) error {
+						Flags: append(commonFlags, reportFlag),
+						Action: withReport(func(c *cli.Context) error {
 							ui.PrintBanner()
 							printWorkPlanFromContext(c)
-							return runPhase(c, "create-story")
-						},
+							return runPhase(c, "create-story", nil)
+						}),
 					},
 					{
 						Name:  "dev-story",
 						Usage: "Run dev-story phase",
-						Flags: commonFlags,
-						Action: func(c *cli.Context) error {
+						Flags: append(commonFlags, reportFlag),
+						Action: withReport(func(c *cli.Context) error {
 							ui.PrintBanner()
 							printWorkPlanFromContext(c)
-							return runPhase(c, "dev-story")
-						},
+							return runPhase(c, "dev-story", nil)
+						}),
 					},
 					{
 						Name:  "code-review",
 						Usage: "Run code-review phase",
-						Flags: commonFlags,
-						Action: func(c *cli.Context) error {
+						Flags: append(commonFlags, reportFlag),
+						Action: withReport(func(c *cli.Context) error {
 							ui.PrintBanner()
 							printWorkPlanFromContext(c)
-							return runPhase(c, "code-review")
-						},
+							return runPhase(c, "code-review", nil)
+						}),
 					},
 					{
 						Name:  "plan-epics",
 						Usage: "Plan the next epic using the prime directive via BMAD correct-course workflow",
 						Flags: append(commonFlags,
 							&cli.StringFlag{
-								Name:  "prime-directive",
-								Usage: "Path to the prime directive file (default: <project-root>/_bmad-output/prime-directive.md)",
+								Name:    "prime-directive",
+								EnvVars: []string{"BMAD_RUNNER_PRIME_DIRECTIVE"},
+								Usage:   "Path to the prime directive file (default: <project-root>/_bmad-output/prime-directive.md)",
 							},
 							&cli.IntFlag{
-								Name:  "max-new-epics",
-								Usage: "Maximum number of new epics to generate",
-								Value: planner.DefaultMaxEpics,
+								Name:    "max-new-epics",
+								EnvVars: []string{"BMAD_RUNNER_MAX_NEW_EPICS"},
+								Usage:   "Maximum number of new epics to generate",
+								Value:   planner.DefaultMaxEpics,
 							},
+							reportFlag,
 						),
-						Action: func(c *cli.Context) error {
+						Action: withReport(func(c *cli.Context) error {
 							ui.PrintBanner()
 							return runPlanEpicsCommand(c)
-						},
+						}),
 					},
 					{
 						Name:  "auto",
 						Usage: "Loop through pending stories and epics until all done; run retrospective when epic completes",
-						Flags: append(commonFlags,
+						Flags: append(append(append(commonFlags, autoFlags...), commitFlags...),
+							&cli.StringFlag{
+								Name:    "worktree",
+								EnvVars: []string{"BMAD_RUNNER_WORKTREE"},
+								Usage:   "Run each story's phases in its own git worktree on branch bmad/<story-key>, then merge the branch back (merge) or leave it for review (keep)",
+							},
 							&cli.IntFlag{
-								Name:  "max-iterations",
-								Usage: "Maximum loop iterations (safety limit)",
-								Value: 50,
+								Name:    "parallel",
+								EnvVars: []string{"BMAD_RUNNER_PARALLEL"},
+								Usage:   "Run up to N stories of an epic at once, each in its own git worktree (implies --worktree merge unless set); stories wait for their depends_on stories",
+								Value:   1,
 							},
-							&cli.BoolFlag{
-								Name:  "no-pause-after-retro",
-								Usage: "Do not prompt after retrospective; continue immediately (for scripts/CI)",
+							&cli.StringFlag{
+								Name:    "on-failure",
+								EnvVars: []string{"BMAD_RUNNER_ON_FAILURE"},
+								Usage:   "What to do with the work of a story whose phase fails: keep it for inspection (keep), restore the state the story started from (rollback), or save it on a bmad-failed/ branch and then roll back (branch)",
+								Value:   onFailureKeep,
 							},
 							&cli.BoolFlag{
-								Name:  "ignore-stall",
-								Usage: "Continue even when sprint-status.yaml is unchanged after a story (avoids exit on workflow sync failures)",
+								Name:    "resume",
+								EnvVars: []string{"BMAD_RUNNER_RESUME"},
+								Usage:   "Continue the interrupted session from the phase it stopped in, with its iteration and epic-planning counts",
 							},
 							&cli.BoolFlag{
-								Name:  "enable-epic-planning",
-								Usage: "When all stories are done, automatically plan the next epic via BMAD create-epics-and-stories + sprint-planning",
-							},
-							&cli.StringFlag{
-								Name:  "prime-directive",
-								Usage: "Path to the prime directive file that guides epic planning (default: <project-root>/_bmad-output/prime-directive.md)",
-							},
-							&cli.IntFlag{
-								Name:  "max-new-epics",
-								Usage: "Maximum number of new epics to plan across this auto session (one per no-work event)",
-								Value: planner.DefaultMaxEpics,
+								Name:    "dry-run",
+								EnvVars: []string{"BMAD_RUNNER_DRY_RUN"},
+								Usage:   "Print the story/phase/agent/model plan for the pending work without starting an agent",
 							},
+							reportFlag,
 						),
-						Action: func(c *cli.Context) error {
+						Action: withReport(func(c *cli.Context) error {
 							ui.PrintBanner()
 							return runAuto(c)
-						},
+						}),
 					},
 				},
-				Action: func(c *cli.Context) error {
+				Action: withReport(func(c *cli.Context) error {
 					ui.PrintBanner()
-					_, statusPath, err := config.ResolveProjectRoot(c.String("status-file"), c.String("project-root"))
+					projectRoot, statusPath, err := config.ResolveProjectRoot(c.String("status-file"), c.String("project-root"))
 					if err != nil {
 						return fmt.Errorf("resolving project root: %w", err)
 					}
+					committer, err := newPhaseCommitter(c, projectRoot)
+					if err != nil {
+						return err
+					}
 					s, err := status.Parse(statusPath)
 					if err != nil {
 						pterm.Warning.Printf("Could not parse sprint-status: %v — running full pipeline\n", err)
 						printWorkPlanFromContext(c)
-						return runFullPipeline(c)
+						return runFullPipeline(c, committer)
 					}
 					action, epicKey, storyKey, found := s.NextWork()
 					w := ui.WorkPlan{
@@ -243,20 +441,120 @@ func main() {
 						return nil
 					}
 					if action == "retrospective" {
-						return runPhase(c, "retrospective")
+						return runPhase(c, "retrospective", committer)
 					}
-					return runFullPipeline(c)
-				},
+					return runFullPipeline(c, committer)
+				}),
 			},
 		},
 	}
 
-	if err := app.Run(os.Args); err != nil {
+	withSettings(app.Commands)
+
+	ctx, stop := signalContext()
+	err := app.RunContext(ctx, os.Args)
+	stop()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		if errors.Is(err, agent.ErrInterrupted) {
+			os.Exit(130)
+		}
 		os.Exit(1)
 	}
 }
 
+// signalContext returns a context that is cancelled on the first SIGINT or SIGTERM.
+// The signal is recorded as the cancellation cause so agent.Runner forwards the same
+// signal to the agent's process group. A second signal exits immediately.
+func signalContext() (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	sigCh := make(chan os.Signal, 2)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig, ok := <-sigCh
+		if !ok {
+			return
+		}
+		pterm.Println()
+		pterm.Warning.Printf("Received %s — stopping agent (send again to force quit)\n", sig)
+		cancel(agent.SignalCause{Signal: sig})
+		if _, ok := <-sigCh; ok {
+			os.Exit(130)
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(sigCh)
+		close(sigCh)
+		cancel(nil)
+	}
+}
+
+// newRunner builds an agent.Runner from the common flags for the given project root.
+// It returns the resolved agent type alongside the runner.
+func newRunner(c *cli.Context, projectRoot string) (*agent.Runner, string, error) {
+	agentType := resolveAgentType(c.String("agent-type"))
+	agentPath, err := config.LookupAgent(c.String("agent-path"), agentType)
+	if err != nil {
+		return nil, "", fmt.Errorf("looking up agent: %w", err)
+	}
+
+	phaseTimeouts, err := config.ParsePhaseTimeouts(c.StringSlice("phase-timeout"))
+	if err != nil {
+		return nil, "", err
+	}
+	if err := config.CheckIdleTimeout(c.Duration("idle-timeout")); err != nil {
+		return nil, "", err
+	}
+
+	r := &agent.Runner{
+		AgentPath:     agentPath,
+		AgentType:     agentType,
+		ProjectRoot:   projectRoot,
+		NoLiveStatus:  c.Bool("no-live-status") || !term.IsTerminal(int(os.Stdout.Fd())),
+		UsePTY:        c.Bool("pty"),
+		ShutdownGrace: c.Duration("shutdown-grace"),
+		PhaseTimeouts: phaseTimeouts,
+		IdleTimeout:   c.Duration("idle-timeout"),
+		Report:        reportOf(c),
+	}
+	if !c.Bool("no-transcripts") {
+		r.TranscriptDir = filepath.Join(projectRoot, runnerLogsDir)
+	}
+	return r, agentType, nil
+}
+
+// runnerLogsDir is where phase transcripts are written, relative to the project root.
+const runnerLogsDir = "_bmad-output/runner-logs"
+
+// currentWorkKey returns the story key — or epic key, for a pending retrospective —
+// that sprint-status.yaml says is next, or "" if it cannot be determined.
+func currentWorkKey(statusPath string) string {
+	s, err := status.Parse(statusPath)
+	if err != nil {
+		return ""
+	}
+	action, epicKey, storyKey, found := s.NextWork()
+	switch {
+	case !found:
+		return ""
+	case action == "retrospective":
+		return epicKey
+	default:
+		return storyKey
+	}
+}
+
+// printInterrupted reports which phase of which work item was interrupted and what
+// remains, so the next invocation can pick up from sprint-status.yaml cleanly.
+func printInterrupted(subject, phase string, remaining []string) {
+	pterm.Println()
+	pterm.Warning.Printf("Interrupted during %s for %s\n", phase, subject)
+	if len(remaining) > 0 {
+		pterm.Info.Printf("Not completed: %s\n", strings.Join(remaining, " → "))
+	}
+	pterm.Info.Println("The agent may have left partial changes; review them, then re-run to continue from sprint-status.yaml.")
+}
+
 func printWorkPlanFromContext(c *cli.Context) {
 	_, statusPath, err := config.ResolveProjectRoot(c.String("status-file"), c.String("project-root"))
 	if err != nil {
@@ -282,11 +580,17 @@ func printWorkPlanFromContext(c *cli.Context) {
 	ui.PrintWorkPlan(w)
 }
 
-func runFullPipeline(c *cli.Context) error {
+// runFullPipeline runs create-story, dev-story and code-review in turn, committing
+// after each with committer (nil for none).
+func runFullPipeline(c *cli.Context, committer *phaseCommitter) error {
 	phases := []string{"create-story", "dev-story", "code-review"}
 	for i, phase := range phases {
 		ui.PrintPipeline(phases, i)
-		if err := runPhase(c, phase); err != nil {
+		if err := runPhase(c, phase, committer); err != nil {
+			if errors.Is(err, agent.ErrInterrupted) {
+				printInterrupted("the full pipeline", phase, phases[i:])
+				return err
+			}
 			pterm.Error.Printf("Phase %s failed: %v\n", phase, err)
 			return err
 		}
@@ -295,50 +599,37 @@ func runFullPipeline(c *cli.Context) error {
 	return nil
 }
 
-func runPhase(c *cli.Context, phase string) error {
-	projectRoot, _, err := config.ResolveProjectRoot(c.String("status-file"), c.String("project-root"))
+// runPhase runs one phase for the current work item and, when it succeeds,
+// commits its changes with committer (nil for none).
+func runPhase(c *cli.Context, phase string, committer *phaseCommitter) error {
+	projectRoot, statusPath, err := config.ResolveProjectRoot(c.String("status-file"), c.String("project-root"))
 	if err != nil {
 		return fmt.Errorf("resolving project root: %w", err)
 	}
 
-	agentType := resolveAgentType(c.String("agent-type"))
-	agentPath, err := config.LookupAgent(c.String("agent-path"), agentType)
+	agents, err := newPhaseAgents(c, projectRoot, phase)
 	if err != nil {
-		return fmt.Errorf("looking up agent: %w", err)
-	}
-
-	model := c.String("model")
-	if model == "" {
-		model = config.DefaultModel(agentType, phase)
+		return err
 	}
+	r, agentType, model := agents.forPhase(phase)
+	r.StoryKey = currentWorkKey(statusPath)
 
-	r := &agent.Runner{
-		AgentPath:    agentPath,
-		AgentType:    agentType,
-		ProjectRoot:  projectRoot,
-		NoLiveStatus: c.Bool("no-live-status") || !term.IsTerminal(int(os.Stdout.Fd())),
+	start := time.Now()
+	if err := r.Run(c.Context, phase, model); err != nil {
+		return err
 	}
-
-	return r.Run(phase, model)
+	return committer.commit("", nil, r.StoryKey, phase, agentType, model, time.Since(start))
 }
 
-func runAuto(c *cli.Context) error {
+func runAuto(c *cli.Context) (retErr error) {
 	projectRoot, statusPath, err := config.ResolveProjectRoot(c.String("status-file"), c.String("project-root"))
 	if err != nil {
 		return fmt.Errorf("resolving project root: %w", err)
 	}
 
-	agentType := resolveAgentType(c.String("agent-type"))
-	agentPath, err := config.LookupAgent(c.String("agent-path"), agentType)
+	agents, err := newPhaseAgents(c, projectRoot, workflowPhases...)
 	if err != nil {
-		return fmt.Errorf("looking up agent: %w", err)
-	}
-
-	r := &agent.Runner{
-		AgentPath:    agentPath,
-		AgentType:    agentType,
-		ProjectRoot:  projectRoot,
-		NoLiveStatus: c.Bool("no-live-status") || !term.IsTerminal(int(os.Stdout.Fd())),
+		return err
 	}
 
 	maxIter := c.Int("max-iterations")
@@ -349,19 +640,117 @@ func runAuto(c *cli.Context) error {
 		maxNewEpics = planner.DefaultMaxEpics
 	}
 
-	var lastStalledStory string
-	var stallCount int
+	parallel := c.Int("parallel")
+	if parallel < 1 {
+		return fmt.Errorf("--parallel %d: want at least 1", parallel)
+	}
+	worktreeMode := c.String("worktree")
+	if parallel > 1 && worktreeMode == "" {
+		worktreeMode = worktreeMerge
+	}
+	repo, err := openWorktreeRepo(worktreeMode, projectRoot)
+	if err != nil {
+		return err
+	}
+	onFailure := c.String("on-failure")
+	failureRepo, err := openFailureRepo(onFailure, projectRoot)
+	if err != nil {
+		return err
+	}
+
+	if c.Bool("dry-run") {
+		// The plan is simulated from sprint-status.yaml one story at a time, so it
+		// cannot show a saved session or concurrent stories.
+		if c.Bool("resume") {
+			return fmt.Errorf("--dry-run cannot be combined with --resume: the plan is built from the status file, not the saved session")
+		}
+		if parallel > 1 {
+			return fmt.Errorf("--dry-run cannot be combined with --parallel %d: the plan only shows the one-story-at-a-time order", parallel)
+		}
+		return printAutoPlan(agents, statusPath, maxIter, enableEpicPlanning)
+	}
+
+	committer, err := newPhaseCommitter(c, projectRoot)
+	if err != nil {
+		return err
+	}
+
+	// The journal checkpoints the session so --resume can pick up an interrupted
+	// story at the phase it stopped in, with the counters below restored.
+	journalPath := filepath.Join(projectRoot, session.DefaultPath)
+	journal, err := openJournal(c, journalPath, statusPath)
+	if err != nil {
+		return err
+	}
+	// Stories interrupted mid-pipeline are resumed one at a time, before anything
+	// else; a --parallel session's stories each continue in their kept worktree.
+	resumeWork := journal.InterruptedStories()
+	for _, w := range journal.Stories {
+		if len(w.Remaining()) > 0 && repo == nil {
+			return fmt.Errorf("story %s of the session to resume ran in a worktree with --parallel; resume it with --parallel or --worktree", w.Story)
+		}
+	}
+	defer func() {
+		if retErr == nil {
+			if err := session.Remove(journalPath); err != nil {
+				pterm.Warning.Printf("%v\n", err)
+			}
+			return
+		}
+		for _, w := range journal.InterruptedStories() {
+			pterm.Info.Printf("Session saved; continue from %s of story %s with: bmad-runner run auto --resume\n", w.Remaining()[0], w.Story)
+		}
+	}()
+
+	lastStalledStory := journal.LastStalledStory
+	stallCount := journal.StallCount
 	// epicPlanningCount tracks how many new epics have been planned this session.
 	// Each "no work found" event plans ONE epic (via one targeted BMAD invocation).
 	// Stops when we reach maxNewEpics to prevent unbounded planning.
-	epicPlanningCount := 0
+	epicPlanningCount := journal.EpicPlanningCount
 
 	primeDirectivePath := c.String("prime-directive")
 	if primeDirectivePath == "" {
 		primeDirectivePath = filepath.Join(projectRoot, planner.DefaultPrimeDirectivePath)
 	}
 
-	for iter := 0; iter < maxIter; iter++ {
+	// With --parallel the stories of an epic run concurrently; retrospectives,
+	// epic planning and a resumed story still run one at a time below.
+	var par *parallelRun
+	if parallel > 1 {
+		par = &parallelRun{
+			c:           c,
+			agents:      agents,
+			n:           parallel,
+			repo:        repo,
+			mode:        worktreeMode,
+			failureRepo: failureRepo,
+			onFailure:   onFailure,
+			committer:   committer,
+			projectRoot: projectRoot,
+			statusPath:  statusPath,
+			ignoreStall: ignoreStall,
+			journal:     journal,
+			journalPath: journalPath,
+			stalls:      map[string]int{},
+		}
+	}
+
+	// snapshot is the state the current story started from, kept across the
+	// retries of a story that times out so --on-failure undoes all of them.
+	var snapshot *storySnapshot
+	failStory := func(err error, wt *storyWorktree) {
+		if snapshot.fail(err, wt, reportOf(c)) {
+			journal.Work = nil
+			saveJournal(journal, journalPath)
+		}
+	}
+
+	for iter := journal.Iteration; iter < maxIter; iter++ {
+		journal.Iteration, journal.EpicPlanningCount = iter, epicPlanningCount
+		journal.LastStalledStory, journal.StallCount = lastStalledStory, stallCount
+		saveJournal(journal, journalPath)
+
 		statusData, err := os.ReadFile(statusPath)
 		if err != nil {
 			return fmt.Errorf("reading status file: %w", err)
@@ -373,6 +762,25 @@ func runAuto(c *cli.Context) error {
 		}
 
 		action, epicKey, storyKey, found := s.NextWork()
+		var runPhases []string
+		var assignment string // keeps the agent on a resumed --parallel story
+		if len(resumeWork) > 0 {
+			w := resumeWork[0]
+			resumeWork = resumeWork[1:]
+			journal.EndStory(w.Story)
+			if st, ok := s.DevStatus[w.Story]; ok && !st.Finished() {
+				action, epicKey, storyKey, found = "story", w.Epic, w.Story, true
+				runPhases = w.Remaining()
+				if w != journal.Work {
+					assignment = storyAssignment(storyKey)
+				}
+				journal.Work = w
+				pterm.Info.Printf("Resuming story %s at %s\n", storyKey, runPhases[0])
+			} else {
+				pterm.Warning.Printf("Story %s of the interrupted session is no longer pending; continuing from sprint-status.yaml\n", w.Story)
+			}
+			saveJournal(journal, journalPath)
+		}
 		if !found {
 			if !enableEpicPlanning {
 				pterm.Success.Println("All work complete!")
@@ -386,7 +794,7 @@ func runAuto(c *cli.Context) error {
 
 			// Plan ONE new epic via a targeted BMAD invocation, then continue.
 			nextEpicNum := s.NextEpicNumber()
-			planErr := runOneEpicPlanning(c, r, statusPath, primeDirectivePath, agentType, nextEpicNum, statusData)
+			planErr := runOneEpicPlanning(c, agents, statusPath, primeDirectivePath, nextEpicNum, statusData, committer)
 			switch planErr {
 			case nil:
 				// Sprint-status was updated — loop will pick up the new stories.
@@ -420,14 +828,23 @@ func runAuto(c *cli.Context) error {
 
 		if action == "retrospective" {
 			pterm.DefaultSection.Printf("Epic %s complete — running retrospective", epicKey)
-
-			phaseModel := c.String("model")
-			if phaseModel == "" {
-				phaseModel = config.DefaultModel(agentType, "retrospective")
+			r, agentType, phaseModel := agents.forPhase("retrospective")
+			r.StoryKey = epicKey
+
+			start, ranModel := time.Now(), phaseModel
+			err := retryPolicy(c, agentType, phaseModel).Do(c.Context, "retrospective", phaseModel, func(model string) error {
+				ranModel = model
+				return r.Run(c.Context, "retrospective", model)
+			})
+			if err != nil {
+				if errors.Is(err, agent.ErrInterrupted) {
+					printInterrupted("epic "+epicKey, "retrospective", nil)
+					return err
+				}
+				pterm.Error.Printf("Retrospective failed (%s): %v\n", agent.Classify(err), err)
+				return err
 			}
-
-			if err := r.Run("retrospective", phaseModel); err != nil {
-				pterm.Error.Printf("Retrospective failed: %v\n", err)
+			if err := committer.commit("", nil, epicKey, "retrospective", agentType, ranModel, time.Since(start)); err != nil {
 				return err
 			}
 			if !c.Bool("no-pause-after-retro") && term.IsTerminal(int(os.Stdin.Fd())) {
@@ -437,32 +854,116 @@ func runAuto(c *cli.Context) error {
 			continue
 		}
 
-		// Run story pipeline dynamically based on story status
-		var runPhases []string
-		storyStatus := s.DevStatus[storyKey]
-		switch storyStatus {
-		case "drafted", "in-progress":
-			runPhases = []string{"dev-story", "code-review"}
-		case "in-review":
-			runPhases = []string{"code-review"}
-		default: // "backlog" or unknown
-			runPhases = []string{"create-story", "dev-story", "code-review"}
+		if par != nil && runPhases == nil {
+			pterm.Info.Printf("Running up to %d stories at once\n", parallel)
+			started, err := par.runEpic(epicKey, maxIter-iter)
+			// Every story started is an iteration, also when the epic stops early.
+			iter += max(started, 1) - 1
+			if err != nil {
+				journal.Iteration = iter
+				saveJournal(journal, journalPath)
+				return err
+			}
+			lastStalledStory, stallCount = "", 0
+			continue
+		}
+
+		// Run the phases the story's state still needs
+		if runPhases == nil {
+			runPhases = s.DevStatus[storyKey].RemainingPhases()
+			journal.StartWork(epicKey, storyKey, runPhases)
+			saveJournal(journal, journalPath)
+		}
+
+		if snapshot == nil || snapshot.story != storyKey {
+			snapshot, err = newStorySnapshot(failureRepo, onFailure, projectRoot, statusPath, storyKey, repo != nil)
+			if err != nil {
+				return err
+			}
+		}
+
+		// With --worktree the story's phases run on its own branch and worktree.
+		var wt *storyWorktree
+		if repo != nil {
+			wt, err = newStoryWorktree(repo, worktreeMode, projectRoot, statusPath, storyKey, nil)
+			if err != nil {
+				return err
+			}
+			pterm.Info.Printf("Worktree: %s (branch %s)\n", wt.dir, wt.branch)
 		}
 
+		var timeoutErr error
 		for i, phase := range runPhases {
 			ui.PrintPipeline(runPhases, i)
 
-			phaseModel := c.String("model")
-			if phaseModel == "" {
-				phaseModel = config.DefaultModel(agentType, phase)
+			r, agentType, phaseModel := agents.forPhase(phase)
+			r.StoryKey = storyKey
+			if wt != nil {
+				r = wt.runner(r)
 			}
 
-			if err := r.Run(phase, phaseModel); err != nil {
-				pterm.Error.Printf("Phase %s failed: %v\n", phase, err)
+			start, ranModel := time.Now(), phaseModel
+			err := retryPolicy(c, agentType, phaseModel).Do(c.Context, phase, phaseModel, func(model string) error {
+				ranModel = model
+				return r.RunPhaseWithContext(c.Context, assignment, phase, model)
+			})
+			if wt != nil {
+				if rerr := wt.reconcile(); rerr != nil {
+					pterm.Warning.Printf("sprint-status.yaml not reconciled from the worktree: %v\n", rerr)
+				}
+			}
+			if err != nil {
+				if errors.Is(err, agent.ErrInterrupted) {
+					printInterrupted("story "+storyKey, phase, runPhases[i:])
+					return err
+				}
+				if agent.IsTimeout(err) {
+					// A hung agent was killed — treat it like a stall and retry the story.
+					timeoutErr = err
+					break
+				}
+				pterm.Error.Printf("Phase %s failed (%s): %v\n", phase, agent.Classify(err), err)
+				failStory(err, wt)
 				return err
 			}
+			// In a worktree the phase is committed on the story branch; its
+			// sprint-status.yaml changes were reconciled to the main tree and are
+			// committed there.
+			if wt != nil {
+				err = committer.commitWorktree(wt, storyKey, phase, agentType, ranModel, time.Since(start))
+			} else {
+				err = committer.commit("", nil, storyKey, phase, agentType, ranModel, time.Since(start))
+			}
+			if err != nil {
+				return err
+			}
+			journal.CompletePhase(phase)
+			saveJournal(journal, journalPath)
+		}
+
+		if timeoutErr != nil {
+			if storyKey == lastStalledStory {
+				stallCount++
+			} else {
+				lastStalledStory = storyKey
+				stallCount = 1
+			}
+			if stallCount >= maxStallRuns {
+				failStory(timeoutErr, wt)
+				return fmt.Errorf("story %s timed out or stalled %d runs in a row: %w", storyKey, maxStallRuns, timeoutErr)
+			}
+			pterm.Warning.Printf("%v — retrying story %s on the next iteration (%d/%d).\n", timeoutErr, storyKey, stallCount, maxStallRuns)
+			pterm.Println()
+			continue
 		}
 
+		if wt != nil {
+			if err := wt.finish(); err != nil {
+				return err
+			}
+		}
+		journal.Work = nil
+		snapshot = nil
 		pterm.Success.Printf("Story %s complete — continuing to next\n", storyKey)
 		pterm.Println()
 
@@ -478,8 +979,8 @@ func runAuto(c *cli.Context) error {
 				lastStalledStory = storyKey
 				stallCount = 1
 			}
-			if !ignoreStall && stallCount >= 3 {
-				return fmt.Errorf("stall detected: sprint-status.yaml unchanged after 3 runs for story %s — update it manually (mark story done) and run again", storyKey)
+			if !ignoreStall && stallCount >= maxStallRuns {
+				return fmt.Errorf("stall detected: sprint-status.yaml unchanged after %d runs for story %s — mark the story done with 'bmad-runner set %s done' (or defer it) and run again", maxStallRuns, storyKey, storyKey)
 			}
 			pterm.Warning.Printf("sprint-status.yaml unchanged — workflow may not have updated it. Continuing to next iteration.\n")
 		} else {
@@ -491,6 +992,22 @@ func runAuto(c *cli.Context) error {
 	return fmt.Errorf("max iterations (%d) reached", maxIter)
 }
 
+// retryPolicy builds the retry policy for one auto-loop phase from the auto flags.
+func retryPolicy(c *cli.Context, agentType, model string) agent.RetryPolicy {
+	p := agent.RetryPolicy{
+		MaxRetries: c.Int("max-retries"),
+		Backoff:    c.Duration("retry-backoff"),
+	}
+	if c.Bool("model-fallback") {
+		p.FallbackModel = config.FallbackModel(agentType, model)
+	}
+	return p
+}
+
+// maxStallRuns is how many consecutive runs of the same story may stall (leave
+// sprint-status.yaml unchanged) or time out before the auto loop gives up.
+const maxStallRuns = 3
+
 // runOneEpicPlanning handles one epic planning cycle when all current work is done.
 //
 // It runs a single BMAD correct-course agent invocation. correct-course is the
@@ -506,12 +1023,16 @@ func runAuto(c *cli.Context) error {
 // Returns nil if new work was successfully staged, or a sentinel error for graceful exits.
 func runOneEpicPlanning(
 	c *cli.Context,
-	r *agent.Runner,
-	statusPath, primeDirectivePath, agentType string,
+	agents *phaseAgents,
+	statusPath, primeDirectivePath string,
 	nextEpicNum int,
 	statusBefore []byte,
+	committer *phaseCommitter,
 ) error {
+	start := time.Now()
 	pterm.DefaultSection.Printf("No stories remain — planning Epic %d via BMAD workflow", nextEpicNum)
+	r, agentType, epicModel := agents.forPhase("correct-course")
+	r.StoryKey = fmt.Sprintf("epic-%d", nextEpicNum)
 
 	// Ensure the prime directive exists; create default if missing.
 	created, err := planner.EnsurePrimeDirective(primeDirectivePath)
@@ -524,7 +1045,6 @@ func runOneEpicPlanning(
 		return errEpicPlanningPrimeDirectiveCreated
 	}
 
-
 	// Discover project files to ground the planning context in actual project state.
 	projectRoot, _, _ := config.ResolveProjectRoot(c.String("status-file"), c.String("project-root"))
 
@@ -550,17 +1070,12 @@ func runOneEpicPlanning(
 	var completedEpics []string
 	if s, parseErr := status.ParseBytes(statusBefore); parseErr == nil {
 		for _, g := range s.EpicGroups() {
-			if s.DevStatus[g.EpicKey] == "done" {
+			if s.DevStatus[g.EpicKey] == status.StateDone {
 				completedEpics = append(completedEpics, g.EpicKey)
 			}
 		}
 	}
 
-	epicModel := c.String("model")
-	if epicModel == "" {
-		epicModel = config.DefaultModel(agentType, "correct-course")
-	}
-
 	// --- Feature Scout: propose one concrete feature before invoking correct-course ---
 	//
 	// The Feature Scout runs as a separate agent step. It reads all project context
@@ -587,7 +1102,11 @@ func runOneEpicPlanning(
 	featureScoutPrompt := planner.BuildFeatureProposalPrompt(epicCtx)
 
 	pterm.DefaultSection.Printf("Running Feature Scout to propose Epic %d", nextEpicNum)
-	scoutErr := r.RunWithPrompt(featureScoutPrompt, "feature-scout", epicModel)
+	scoutErr := r.RunWithPrompt(c.Context, featureScoutPrompt, "feature-scout", epicModel)
+	if errors.Is(scoutErr, agent.ErrInterrupted) {
+		printInterrupted(fmt.Sprintf("Epic %d planning", nextEpicNum), "feature-scout", []string{"feature-scout", "correct-course"})
+		return scoutErr
+	}
 	if scoutErr != nil {
 		pterm.Warning.Printf("Feature Scout failed (%v) — falling back to prime directive only.\n", scoutErr)
 	} else {
@@ -606,7 +1125,11 @@ func runOneEpicPlanning(
 	correctCourseContext := planner.BuildCorrectCourseContext(epicCtx)
 
 	pterm.Info.Printf("Running correct-course to plan Epic %d\n", nextEpicNum)
-	if err := r.RunPhaseWithContext(correctCourseContext, "correct-course", epicModel); err != nil {
+	if err := r.RunPhaseWithContext(c.Context, correctCourseContext, "correct-course", epicModel); err != nil {
+		if errors.Is(err, agent.ErrInterrupted) {
+			printInterrupted(fmt.Sprintf("Epic %d planning", nextEpicNum), "correct-course", []string{"correct-course"})
+			return err
+		}
 		pterm.Error.Printf("correct-course failed: %v\n", err)
 		return err
 	}
@@ -620,6 +1143,9 @@ func runOneEpicPlanning(
 		pterm.Warning.Println("correct-course did not update sprint-status.yaml — no new stories detected.")
 		return errEpicPlanningNoNewWork
 	}
+	if err := committer.commit("", nil, r.StoryKey, "correct-course", agentType, epicModel, time.Since(start)); err != nil {
+		return err
+	}
 
 	pterm.Success.Printf("Epic %d staged — continuing auto loop.\n", nextEpicNum)
 	pterm.Println()
@@ -641,17 +1167,9 @@ func runPlanEpicsCommand(c *cli.Context) error {
 		return fmt.Errorf("resolving project root: %w", err)
 	}
 
-	agentType := resolveAgentType(c.String("agent-type"))
-	agentPath, err := config.LookupAgent(c.String("agent-path"), agentType)
+	agents, err := newPhaseAgents(c, projectRoot, "correct-course")
 	if err != nil {
-		return fmt.Errorf("looking up agent: %w", err)
-	}
-
-	r := &agent.Runner{
-		AgentPath:    agentPath,
-		AgentType:    agentType,
-		ProjectRoot:  projectRoot,
-		NoLiveStatus: c.Bool("no-live-status") || !term.IsTerminal(int(os.Stdout.Fd())),
+		return err
 	}
 
 	primeDirectivePath := c.String("prime-directive")
@@ -670,7 +1188,7 @@ func runPlanEpicsCommand(c *cli.Context) error {
 	}
 
 	nextEpicNum := s.NextEpicNumber()
-	planErr := runOneEpicPlanning(c, r, statusPath, primeDirectivePath, agentType, nextEpicNum, statusData)
+	planErr := runOneEpicPlanning(c, agents, statusPath, primeDirectivePath, nextEpicNum, statusData, nil)
 	switch planErr {
 	case nil:
 		pterm.Success.Printf("Epic %d planning complete.\n", nextEpicNum)
@@ -692,15 +1210,14 @@ var (
 	errEpicPlanningPrimeDirectiveCreated = fmt.Errorf("prime directive created: review and re-run")
 )
 
+// resolveAgentType returns the registered backend name for s, falling back to the
+// default backend (with a warning) for names no backend is registered under.
 func resolveAgentType(s string) string {
-	switch s {
-	case config.AgentTypeClaudeCode:
-		return config.AgentTypeClaudeCode
-	case config.AgentTypeGeminiCLI:
-		return config.AgentTypeGeminiCLI
-	case config.AgentTypeOpenCode:
-		return config.AgentTypeOpenCode
-	default:
-		return config.AgentTypeCursorAgent
+	if _, ok := agent.LookupBackend(s); ok {
+		return s
+	}
+	if s != "" {
+		pterm.Warning.Printf("Unknown agent type %q (known: %s); using %s\n", s, strings.Join(agent.BackendNames(), ", "), agent.DefaultBackend)
 	}
+	return agent.DefaultBackend
 }
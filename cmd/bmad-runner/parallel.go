@@ -0,0 +1,293 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/MBFrosty/BMAD-Runner/internal/agent"
+	"github.com/MBFrosty/BMAD-Runner/internal/gitops"
+	"github.com/MBFrosty/BMAD-Runner/internal/session"
+	"github.com/MBFrosty/BMAD-Runner/internal/status"
+	"github.com/MBFrosty/BMAD-Runner/internal/ui"
+
+	"github.com/pterm/pterm"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+)
+
+// statusWriter applies changes to the main tree one at a time on its own
+// goroutine: sprint-status updates and the git operations that touch the main
+// repository. With --parallel every story worktree goes through the same writer,
+// so concurrent stories never interleave their read-modify-write cycles on
+// sprint-status.yaml. A nil *statusWriter applies changes directly.
+type statusWriter struct {
+	ops chan func()
+}
+
+func newStatusWriter() *statusWriter {
+	w := &statusWriter{ops: make(chan func())}
+	go func() {
+		for op := range w.ops {
+			op()
+		}
+	}()
+	return w
+}
+
+// do runs fn on the writer goroutine and returns its error. fn must not call do.
+func (w *statusWriter) do(fn func() error) error {
+	if w == nil {
+		return fn()
+	}
+	done := make(chan error, 1)
+	w.ops <- func() { done <- fn() }
+	return <-done
+}
+
+// setStatus sets key to value in the sprint-status file at path.
+func (w *statusWriter) setStatus(path, key string, value status.State) error {
+	return w.do(func() error { return status.SetStatus(path, key, value) })
+}
+
+// close stops the writer once the pending changes are applied.
+func (w *statusWriter) close() {
+	if w != nil {
+		close(w.ops)
+	}
+}
+
+// parallelRun runs the stories of an epic concurrently for run auto --parallel,
+// each in its own worktree with its own agent.Runner.
+type parallelRun struct {
+	c           *cli.Context
+	agents      *phaseAgents
+	n           int // stories at once
+	repo        *gitops.Repo
+	mode        string // --worktree mode
+	failureRepo *gitops.Repo
+	onFailure   string
+	committer   *phaseCommitter
+	projectRoot string
+	statusPath  string
+	ignoreStall bool
+	journal     *session.Journal // updated on the writer
+	journalPath string
+
+	writer *statusWriter
+	board  *ui.StoryBoard
+	stalls map[string]int // consecutive stalled or timed-out runs per story
+}
+
+// storyOutcome is how one story's run in a worktree ended.
+type storyOutcome struct {
+	story   string
+	err     error // the story failed, or was interrupted
+	timeout error // a phase timed out; the story is retried
+	stalled bool  // all phases ran but the story's status did not change
+}
+
+// runEpic runs the pending stories of epicKey, up to p.n at a time, until every
+// story of the epic is finished, budget stories have been started, or a story
+// fails. A story starts once the stories it depends on (depends_on) are
+// finished. It returns how many story runs it started, which count as auto-loop
+// iterations.
+func (p *parallelRun) runEpic(epicKey string, budget int) (started int, err error) {
+	p.writer = newStatusWriter()
+	defer p.writer.close()
+	p.board = ui.NewStoryBoard(!p.c.Bool("no-live-status") && term.IsTerminal(int(os.Stdout.Fd())))
+	defer p.board.Stop()
+
+	running := map[string]bool{}
+	results := make(chan storyOutcome)
+	for {
+		// Start every story that is ready, unless a story has failed or the
+		// session is being interrupted: then only wait for the running ones.
+		if err == nil && p.c.Context.Err() == nil {
+			s, perr := status.Parse(p.statusPath)
+			if perr != nil {
+				err = fmt.Errorf("parsing status file: %w", perr)
+			} else {
+				for _, key := range s.ReadyStories(epicKey) {
+					if len(running) >= p.n || started >= budget {
+						break
+					}
+					if running[key] {
+						continue
+					}
+					running[key] = true
+					started++
+					from, lastChance := s.DevStatus[key], p.stalls[key] == maxStallRuns-1
+					go func() { results <- p.runStory(epicKey, key, from, lastChance) }()
+				}
+				if len(running) == 0 && started < budget {
+					err = blockedError(s, epicKey)
+				}
+			}
+		}
+		if len(running) == 0 {
+			return started, err
+		}
+
+		out := <-results
+		delete(running, out.story)
+		p.board.Remove(out.story)
+		switch {
+		case out.err != nil:
+			if err == nil {
+				err = out.err
+			}
+		case out.timeout != nil:
+			p.stalls[out.story]++
+			if p.stalls[out.story] < maxStallRuns {
+				pterm.Warning.Printf("%v — retrying story %s (%d/%d)\n", out.timeout, out.story, p.stalls[out.story], maxStallRuns)
+			} else if err == nil {
+				err = fmt.Errorf("story %s timed out or stalled %d runs in a row: %w", out.story, maxStallRuns, out.timeout)
+			}
+		case out.stalled:
+			p.stalls[out.story]++
+			if p.ignoreStall || p.stalls[out.story] < maxStallRuns {
+				pterm.Warning.Printf("Status of %s unchanged — workflow may not have updated it; it will run again\n", out.story)
+			} else if err == nil {
+				err = fmt.Errorf("stall detected: sprint-status.yaml unchanged after %d runs for story %s — mark the story done with 'bmad-runner set %s done' (or defer it) and run again", maxStallRuns, out.story, out.story)
+			}
+		default:
+			delete(p.stalls, out.story)
+		}
+	}
+}
+
+// blockedError reports why no story of epicKey can start although none is
+// running: every pending story waits on depends_on stories that cannot finish
+// first. It returns nil if the epic has no pending stories left.
+func blockedError(s *status.SprintStatus, epicKey string) error {
+	var waits []string
+	for _, g := range s.EpicGroups() {
+		if g.EpicKey != epicKey {
+			continue
+		}
+		for _, st := range g.Stories {
+			if !st.Value.Finished() {
+				waits = append(waits, fmt.Sprintf("%s waits on %s", st.Key, strings.Join(s.Blockers(st.Key), ", ")))
+			}
+		}
+	}
+	if len(waits) == 0 {
+		return nil
+	}
+	return fmt.Errorf("no story of %s can start; check depends_on in sprint-status.yaml: %s", epicKey, strings.Join(waits, "; "))
+}
+
+// checkpoint applies change to the session journal and saves it, on the writer.
+func (p *parallelRun) checkpoint(change func(j *session.Journal)) {
+	p.writer.do(func() error {
+		change(p.journal)
+		saveJournal(p.journal, p.journalPath)
+		return nil
+	})
+}
+
+// runStory runs the phases story of epic still needs, starting from status from,
+// in its worktree, then finishes the worktree. With lastChance a timeout is the
+// story's last allowed one, so the --on-failure action applies to it. The story's
+// progress is journaled so that --resume can continue it at the phase it stopped in.
+func (p *parallelRun) runStory(epic, story string, from status.State, lastChance bool) storyOutcome {
+	out := storyOutcome{story: story}
+	p.board.SetPhase(story, "starting")
+	phases := from.RemainingPhases()
+	p.checkpoint(func(j *session.Journal) { j.StartStory(epic, story, phases) })
+
+	snapshot, err := newStorySnapshot(p.failureRepo, p.onFailure, p.projectRoot, p.statusPath, story, true)
+	if err != nil {
+		out.err = err
+		return out
+	}
+	wt, err := newStoryWorktree(p.repo, p.mode, p.projectRoot, p.statusPath, story, p.writer)
+	if err != nil {
+		out.err = err
+		return out
+	}
+
+	ctx := p.c.Context
+	for _, phase := range phases {
+		p.board.SetPhase(story, phase)
+		r, agentType, phaseModel := p.agents.forPhase(phase)
+		r = wt.runner(r) // a copy: the phase agents' runners are shared
+		r.StoryKey = story
+		r.Progress = func(lines []string) { p.board.Output(story, lines) }
+		r.Output = p.board.Writer(story) // --no-live-status: the agent's output, labelled
+
+		start, ranModel := time.Now(), phaseModel
+		err := retryPolicy(p.c, agentType, phaseModel).Do(ctx, phase, phaseModel, func(model string) error {
+			ranModel = model
+			return r.RunPhaseWithContext(ctx, storyAssignment(story), phase, model)
+		})
+		if rerr := wt.reconcile(); rerr != nil {
+			pterm.Warning.Printf("%s: sprint-status.yaml not reconciled from the worktree: %v\n", story, rerr)
+		}
+		switch {
+		case errors.Is(err, agent.ErrInterrupted):
+			pterm.Warning.Printf("Interrupted during %s for story %s; its worktree is kept\n", phase, story)
+			out.err = err
+			return out
+		case agent.IsTimeout(err):
+			if lastChance {
+				p.fail(snapshot, err, wt)
+			}
+			out.timeout = fmt.Errorf("story %s: %w", story, err)
+			return out
+		case err != nil:
+			pterm.Error.Printf("Story %s: phase %s failed (%s): %v\n", story, phase, agent.Classify(err), err)
+			p.fail(snapshot, err, wt)
+			out.err = fmt.Errorf("story %s: %w", story, err)
+			return out
+		}
+		if err := p.committer.commitWorktree(wt, story, phase, agentType, ranModel, time.Since(start)); err != nil {
+			out.err = err
+			return out
+		}
+		p.checkpoint(func(j *session.Journal) { j.CompleteStoryPhase(story, phase) })
+	}
+
+	p.board.SetPhase(story, "finishing")
+	if err := wt.finish(); err != nil {
+		out.err = err
+		return out
+	}
+	p.checkpoint(func(j *session.Journal) { j.EndStory(story) })
+	s, err := status.Parse(p.statusPath)
+	if err != nil {
+		out.err = fmt.Errorf("parsing status file: %w", err)
+		return out
+	}
+	if st := s.DevStatus[story]; st == from {
+		out.stalled = true
+	} else {
+		pterm.Success.Printf("Story %s: %s → %s\n", story, ui.StatusIcon(from), ui.StatusIcon(st))
+	}
+	return out
+}
+
+// fail applies the --on-failure action to the failed story; once its work is
+// undone the journal no longer has it to resume.
+func (p *parallelRun) fail(snapshot *storySnapshot, cause error, wt *storyWorktree) {
+	if snapshot.fail(cause, wt, reportOf(p.c)) {
+		p.checkpoint(func(j *session.Journal) { j.EndStory(snapshot.story) })
+	}
+}
+
+// storyAssignment is the context block that keeps a --parallel agent on its own
+// story: BMAD workflows otherwise pick the first pending story in sprint-status.yaml,
+// which would be the same for every worktree.
+func storyAssignment(story string) string {
+	return fmt.Sprintf(`## Story assignment
+
+Other agents are working on other stories of this epic at the same time, each in
+its own copy of the repository. Work ONLY on story `+"`%s`"+`: wherever this workflow
+picks the next story from sprint-status.yaml, use `+"`%s`"+`, and change no other
+story's entry.
+
+`, story, story)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MBFrosty/BMAD-Runner/internal/agent"
+	"github.com/MBFrosty/BMAD-Runner/internal/config"
+
+	"github.com/pterm/pterm"
+	"github.com/urfave/cli/v2"
+)
+
+// reportFlag is accepted by the run commands.
+var reportFlag = &cli.StringFlag{
+	Name:    "report",
+	EnvVars: []string{"BMAD_RUNNER_REPORT"},
+	Usage:   "Write a JSON summary of every phase run (story, phase, agent, model, timing, result, sprint-status changes) to this file",
+}
+
+const reportMetadataKey = "report"
+
+// withReport wraps a run action so that, with --report, every phase it runs is
+// recorded and the summary is written when the action returns, whether it
+// succeeded, failed or was interrupted.
+func withReport(action cli.ActionFunc) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		path := c.String("report")
+		if path == "" {
+			return action(c)
+		}
+		projectRoot, statusPath, err := config.ResolveProjectRoot(c.String("status-file"), c.String("project-root"))
+		if err != nil {
+			return fmt.Errorf("resolving project root: %w", err)
+		}
+		rep := agent.NewReport(commandPath(c), projectRoot, statusPath)
+		if c.App.Metadata == nil {
+			c.App.Metadata = map[string]interface{}{}
+		}
+		c.App.Metadata[reportMetadataKey] = rep
+
+		err = action(c)
+		if werr := rep.WriteFile(path, err); werr != nil {
+			if err != nil {
+				pterm.Warning.Printf("Run report not written: %v\n", werr)
+				return err
+			}
+			return werr
+		}
+		pterm.Info.Printf("Run report: %s\n", path)
+		return err
+	}
+}
+
+// commandPath returns the invoked command, e.g. "bmad-runner run auto".
+func commandPath(c *cli.Context) string {
+	var names []string
+	for _, ctx := range c.Lineage() {
+		if ctx.Command != nil && ctx.Command.Name != "" {
+			names = append([]string{ctx.Command.Name}, names...)
+		}
+	}
+	return strings.Join(names, " ")
+}
+
+// reportOf returns the report withReport started for this invocation, or nil.
+func reportOf(c *cli.Context) *agent.Report {
+	rep, _ := c.App.Metadata[reportMetadataKey].(*agent.Report)
+	return rep
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MBFrosty/BMAD-Runner/internal/config"
+	"github.com/MBFrosty/BMAD-Runner/internal/status"
+	"github.com/MBFrosty/BMAD-Runner/internal/ui"
+
+	"github.com/pterm/pterm"
+	"github.com/urfave/cli/v2"
+)
+
+// statusTarget picks the new status for a development_status key of the given
+// kind (status.KindStory, ...) that currently has status current.
+type statusTarget func(kind status.Kind, current status.State) (status.State, error)
+
+// runSet is the action for `bmad-runner set <key> <status>`.
+func runSet(c *cli.Context) error {
+	if c.NArg() != 2 {
+		return fmt.Errorf("usage: bmad-runner set [flags] <key> <status>")
+	}
+	to := status.State(c.Args().Get(1))
+	return setStatus(c, c.Args().Get(0), func(status.Kind, status.State) (status.State, error) { return to, nil }, true)
+}
+
+// statusShortcut returns the action for a command such as `defer <key>` that moves
+// one key to the status target picks. Shortcuts are deliberate moves, so they do
+// not warn about skipped or repeated phases.
+func statusShortcut(name string, target statusTarget) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		if c.NArg() != 1 {
+			return fmt.Errorf("usage: bmad-runner %s [flags] <key>", name)
+		}
+		return setStatus(c, c.Args().First(), target, false)
+	}
+}
+
+// deferTarget defers a story; the auto loop treats deferred stories as finished.
+func deferTarget(kind status.Kind, current status.State) (status.State, error) {
+	if kind != status.KindStory {
+		return "", fmt.Errorf("only stories can be deferred")
+	}
+	return status.StateDeferred, nil
+}
+
+// resetTarget moves a key back to its initial status.
+func resetTarget(kind status.Kind, current status.State) (status.State, error) {
+	if kind == status.KindRetrospective {
+		return status.StateOptional, nil
+	}
+	return status.StateBacklog, nil
+}
+
+// reopenTarget moves a finished story or epic back to in-progress, and a finished
+// retrospective back to optional.
+func reopenTarget(kind status.Kind, current status.State) (status.State, error) {
+	if !current.Finished() {
+		return "", fmt.Errorf("only done or deferred entries can be reopened (status is %s)", current)
+	}
+	if kind == status.KindRetrospective {
+		return status.StateOptional, nil
+	}
+	return status.StateInProgress, nil
+}
+
+// setStatus moves key to the status target picks, after checking that the key
+// exists and the status is a BMAD status for its kind (unless --force). With warn,
+// unusual transitions such as backlog straight to done are reported as warnings.
+func setStatus(c *cli.Context, key string, target statusTarget, warn bool) error {
+	_, statusPath, err := config.ResolveProjectRoot(c.String("status-file"), c.String("project-root"))
+	if err != nil {
+		return fmt.Errorf("resolving project root: %w", err)
+	}
+	s, err := status.Parse(statusPath)
+	if err != nil {
+		return fmt.Errorf("parsing status file: %w", err)
+	}
+	current, ok := s.DevStatus[key]
+	if !ok {
+		return fmt.Errorf("%s has no development_status entry %q", statusPath, key)
+	}
+
+	kind := status.KeyKind(key)
+	to, err := target(kind, current)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	if kind != "" && !to.Valid(kind) && !c.Bool("force") {
+		var known []string
+		for _, st := range status.States(kind) {
+			known = append(known, string(st))
+		}
+		return fmt.Errorf("%q is not a %s status (known: %s); use --force to set it anyway", to, kind, strings.Join(known, ", "))
+	}
+	if to == current {
+		pterm.Info.Printf("%s is already %s\n", key, to)
+		return nil
+	}
+
+	if w := status.TransitionWarning(kind, current, to); warn && w != "" {
+		pterm.Warning.Printf("%s: %s\n", key, w)
+	}
+	if kind == status.KindEpic && to == "done" {
+		if done, total := s.EpicProgress(key); done < total {
+			pterm.Warning.Printf("%s: %d of %d stories are not done or deferred\n", key, total-done, total)
+		}
+	}
+
+	if err := status.SetStatus(statusPath, key, to); err != nil {
+		return err
+	}
+	pterm.Success.Printf("%s: %s → %s\n", key, ui.StatusIcon(current), ui.StatusIcon(to))
+	return nil
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MBFrosty/BMAD-Runner/internal/agent"
+	"github.com/MBFrosty/BMAD-Runner/internal/config"
+
+	"github.com/pterm/pterm"
+	"github.com/urfave/cli/v2"
+)
+
+// modelPhases are the phases whose models config show reports, plus the special keys.
+var modelPhases = []string{"create-story", "dev-story", "code-review", "retrospective", "correct-course", "sprint-planning", "default", "fallback"}
+
+// loadSettings loads the project and user config files for the project selected by
+// c's flags, registers their custom backends and merges their per-phase models,
+// then the BMAD_RUNNER_MODEL_<PHASE> variables on top for every backend.
+func loadSettings(c *cli.Context) (*config.Resolver, error) {
+	projectRoot, _, err := config.ResolveProjectRoot(c.String("status-file"), c.String("project-root"))
+	if err != nil {
+		return nil, fmt.Errorf("resolving project root: %w", err)
+	}
+	res, err := config.LoadResolver(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	if err := agent.RegisterCustomBackends(res.Backends()); err != nil {
+		return nil, fmt.Errorf("config file: %w", err)
+	}
+	res.ApplyModels()
+	envModels := config.EnvModels()
+	for _, name := range agent.BackendNames() {
+		config.OverrideModels(name, envModels)
+	}
+	return res, nil
+}
+
+// applySettings is the Before hook of every command that takes the common flags: it
+// fills flags the user did not set from the config files, so the rest of the CLI
+// reads one set of values with flag > env > project > user > default precedence.
+// The resolver is kept in the app metadata for settingSource.
+func applySettings(c *cli.Context) error {
+	res, err := loadSettings(c)
+	if err != nil {
+		return err
+	}
+	applied := map[string]config.Layer{}
+	for _, key := range config.SettingKeys {
+		if c.IsSet(key) || !hasFlag(c.Command, key) {
+			continue
+		}
+		if v, layer, ok := res.Lookup(key); ok {
+			if err := c.Set(key, v); err != nil {
+				return fmt.Errorf("%s: %s: %w", layer.Path, key, err)
+			}
+			applied[key] = layer
+		}
+	}
+	if c.App.Metadata == nil {
+		c.App.Metadata = map[string]interface{}{}
+	}
+	c.App.Metadata[settingsMetadataKey] = appliedSettings{resolver: res, layers: applied}
+	return nil
+}
+
+const settingsMetadataKey = "settings"
+
+// appliedSettings records which flags applySettings filled from which config file.
+type appliedSettings struct {
+	resolver *config.Resolver
+	layers   map[string]config.Layer
+}
+
+func settingsOf(c *cli.Context) appliedSettings {
+	s, _ := c.App.Metadata[settingsMetadataKey].(appliedSettings)
+	return s
+}
+
+// withSettings installs applySettings on cmds (recursively) that take --agent-type.
+func withSettings(cmds []*cli.Command) {
+	for _, cmd := range cmds {
+		if cmd.Before == nil && hasFlag(cmd, "agent-type") {
+			cmd.Before = applySettings
+		}
+		withSettings(cmd.Subcommands)
+	}
+}
+
+func hasFlag(cmd *cli.Command, name string) bool {
+	if cmd == nil {
+		return false
+	}
+	for _, f := range cmd.Flags {
+		if slices.Contains(f.Names(), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// settingSource reports the effective value of the flag key and where it came from.
+func settingSource(c *cli.Context, key string) (value string, source config.Source, origin string) {
+	value = fmt.Sprint(c.Value(key))
+	if layer, ok := settingsOf(c).layers[key]; ok {
+		return value, layer.Source, layer.Path
+	}
+	if c.IsSet(key) {
+		if name, ok := envSource(c, key); ok {
+			return value, config.SourceEnv, name
+		}
+		return value, config.SourceFlag, "--" + key
+	}
+	return value, config.SourceDefault, ""
+}
+
+// envSource returns the environment variable the flag key took its value from.
+// urfave/cli reports env-provided flags as set, so a flag counts as coming from
+// its variable when the variable is set and holds the flag's current value.
+func envSource(c *cli.Context, key string) (string, bool) {
+	for _, f := range c.Command.Flags {
+		ef, ok := f.(interface{ GetEnvVars() []string })
+		if !ok || !slices.Contains(f.Names(), key) {
+			continue
+		}
+		for _, name := range ef.GetEnvVars() {
+			v, ok := os.LookupEnv(name)
+			if !ok {
+				continue
+			}
+			v = strings.TrimSpace(v)
+			switch f.(type) {
+			case *cli.DurationFlag:
+				d, err := time.ParseDuration(v)
+				return name, err == nil && d == c.Duration(key)
+			case *cli.StringSliceFlag:
+				parts := strings.Split(v, ",")
+				for i := range parts {
+					parts[i] = strings.TrimSpace(parts[i])
+				}
+				return name, slices.Equal(parts, c.StringSlice(key))
+			}
+			return name, v == fmt.Sprint(c.Value(key))
+		}
+	}
+	return "", false
+}
+
+// runConfigShow prints every setting a config file can provide with its effective
+// value and source, then the effective agent and model for each phase.
+func runConfigShow(c *cli.Context) error {
+	res := settingsOf(c).resolver
+	if res == nil {
+		return fmt.Errorf("configuration not loaded")
+	}
+
+	pterm.DefaultHeader.WithFullWidth().Println("Effective configuration")
+	for _, l := range res.Layers() {
+		state := "not found"
+		if exists(l.Path) {
+			state = "loaded"
+		}
+		pterm.Info.Printf("%-7s config: %s (%s)\n", l.Source, l.Path, state)
+	}
+	pterm.Println()
+
+	tableData := pterm.TableData{{"Setting", "Value", "Source", "From"}}
+	for _, key := range config.SettingKeys {
+		value, source, origin := settingSource(c, key)
+		if value == "" {
+			value = pterm.Gray("(none)")
+		}
+		tableData = append(tableData, []string{key, value, string(source), origin})
+	}
+	pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+
+	agentTypeValue, _, _ := settingSource(c, config.KeyAgentType)
+	agentType := resolveAgentType(agentTypeValue)
+	modelValue, modelSource, modelOrigin := settingSource(c, config.KeyModel)
+	_, modelFromFile := settingsOf(c).layers[config.KeyModel] // gives way to BMAD_RUNNER_MODEL_<PHASE>
+	phaseAgents, err := phaseAgentSources(c, res)
+	if err != nil {
+		return err
+	}
+
+	pterm.Println()
+	pterm.DefaultSection.Printf("Agents and models by phase (agent type: %s)", agentType)
+	tableData = pterm.TableData{{"Phase", "Agent", "Model", "Source", "From"}}
+	for _, phase := range modelPhases {
+		phaseAgent, mapped := phaseAgents[phase]
+		rowAgent := agentType
+		if mapped {
+			rowAgent = phaseAgent.AgentType
+		}
+		overrides := res.Models(rowAgent)
+		for p, o := range config.EnvModels() {
+			overrides[p] = o
+		}
+
+		row := []string{phase, rowAgent, config.DefaultModel(rowAgent, phase), string(config.SourceDefault), ""}
+		switch {
+		case mapped && phaseAgent.Model != "":
+			row = []string{phase, rowAgent, phaseAgent.Model, string(phaseAgent.Layer.Source), phaseAgent.Layer.Path}
+		case modelValue != "" && phase != "fallback" && rowAgent == agentType && !(modelFromFile && overrides[phase].Layer.Source == config.SourceEnv):
+			row = []string{phase, rowAgent, modelValue, string(modelSource), modelOrigin}
+		case overrides[phase].Model != "":
+			o := overrides[phase]
+			origin := o.Layer.Path + ": models." + rowAgent + "." + phase
+			if o.Layer.Source == config.SourceEnv {
+				origin = o.Layer.Path
+			}
+			row = []string{phase, rowAgent, o.Model, string(o.Layer.Source), origin}
+		}
+		if mapped && phaseAgent.Model == "" {
+			row[1] += pterm.Gray(fmt.Sprintf(" (%s)", phaseAgent.Layer.Source))
+		}
+		tableData = append(tableData, row)
+	}
+	pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+	return nil
+}
+
+// phaseAgentSources returns the effective phase -> agent mapping like
+// phaseAgentMapping, with the layer each entry came from. Entries from
+// --phase-agent get a pseudo-layer whose path is the flag or variable name.
+func phaseAgentSources(c *cli.Context, res *config.Resolver) (map[string]config.PhaseAgentOverride, error) {
+	out := res.PhaseAgents()
+	for phase, o := range out {
+		o.Layer.Path += ": phase_agents." + phase
+		out[phase] = o
+	}
+	flags, err := config.ParsePhaseAgents(c.StringSlice("phase-agent"))
+	if err != nil {
+		return nil, err
+	}
+	_, source, origin := settingSource(c, "phase-agent")
+	for phase, p := range flags {
+		out[phase] = config.PhaseAgentOverride{PhaseAgent: p, Layer: config.Layer{Source: source, Path: origin}}
+	}
+	return out, nil
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/MBFrosty/BMAD-Runner/internal/config"
+	"github.com/MBFrosty/BMAD-Runner/internal/status"
+	"github.com/MBFrosty/BMAD-Runner/internal/ui"
+
+	"github.com/pterm/pterm"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// statusFilter selects the development_status entries the status command shows.
+type statusFilter struct {
+	epics   []string       // epic keys; empty = all
+	states  []status.State // empty = all
+	pending bool           // only entries that are not finished
+}
+
+// newStatusFilter reads --epic, --state and --pending. Epics may be given as "3"
+// or "epic-3".
+func newStatusFilter(c *cli.Context) (statusFilter, error) {
+	f := statusFilter{pending: c.Bool("pending")}
+	for _, e := range c.StringSlice("epic") {
+		if !strings.HasPrefix(e, "epic-") {
+			e = "epic-" + e
+		}
+		if status.KeyKind(e) != status.KindEpic {
+			return f, fmt.Errorf("--epic %s: want an epic number such as 3 or epic-3", strings.TrimPrefix(e, "epic-"))
+		}
+		f.epics = append(f.epics, e)
+	}
+	for _, s := range c.StringSlice("state") {
+		st := status.State(s)
+		if !st.Valid(status.KindStory) && !st.Valid(status.KindEpic) && !st.Valid(status.KindRetrospective) {
+			return f, fmt.Errorf("--state %s: unknown status", s)
+		}
+		f.states = append(f.states, st)
+	}
+	return f, nil
+}
+
+// match reports whether an entry with state st passes the state filters.
+func (f statusFilter) match(st status.State) bool {
+	if f.pending && st.Finished() {
+		return false
+	}
+	return len(f.states) == 0 || slices.Contains(f.states, st)
+}
+
+// apply drops the epics and entries of sum that the filters exclude. An epic is
+// kept when it or any of its entries matches; its progress counts all stories.
+func (f statusFilter) apply(sum status.Summary) status.Summary {
+	epics := []status.EpicSummary{}
+	for _, e := range sum.Epics {
+		if len(f.epics) > 0 && !slices.Contains(f.epics, e.Key) {
+			continue
+		}
+		stories := []status.OrderedEntry{}
+		for _, st := range e.Stories {
+			if f.match(st.Value) {
+				stories = append(stories, st)
+			}
+		}
+		e.Stories = stories
+		if e.Retrospective != nil && !f.match(e.Retrospective.Value) {
+			e.Retrospective = nil
+		}
+		if len(e.Stories) == 0 && e.Retrospective == nil && !f.match(e.Status) {
+			continue
+		}
+		epics = append(epics, e)
+	}
+	sum.Epics = epics
+	return sum
+}
+
+// runStatus is the action for `bmad-runner status`: entries in document order,
+// grouped under their epics with a progress bar per epic and the next work item
+// highlighted, or the same data as JSON or YAML with --output.
+func runStatus(c *cli.Context) error {
+	_, statusPath, err := config.ResolveProjectRoot(c.String("status-file"), c.String("project-root"))
+	if err != nil {
+		return fmt.Errorf("resolving project root: %w", err)
+	}
+	filter, err := newStatusFilter(c)
+	if err != nil {
+		return err
+	}
+	output := c.String("output")
+	if output != "table" && output != "json" && output != "yaml" {
+		return fmt.Errorf("--output %s: want table, json or yaml", output)
+	}
+
+	s, err := status.Parse(statusPath)
+	if err != nil {
+		return fmt.Errorf("parsing status file: %w", err)
+	}
+	sum := filter.apply(s.Summary())
+
+	switch output {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(sum)
+	case "yaml":
+		enc := yaml.NewEncoder(os.Stdout)
+		enc.SetIndent(2)
+		if err := enc.Encode(sum); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+
+	pterm.DefaultHeader.WithFullWidth().Println("Sprint Status: " + s.Project)
+	pterm.Info.Printf("File: %s\n", statusPath)
+	pterm.Info.Printf("Generated: %s\n", s.Generated)
+	var next string
+	switch {
+	case sum.Next == nil:
+		pterm.Success.Println("All work complete — nothing pending")
+	case sum.Next.Action == "retrospective":
+		next = sum.Next.Key
+		pterm.Info.Printf("Next: retrospective for %s\n", sum.Next.Epic)
+	default:
+		next = sum.Next.Key
+		pterm.Info.Printf("Next: story %s (%s)\n", sum.Next.Key, sum.Next.Epic)
+	}
+	pterm.Println()
+
+	tableData := pterm.TableData{
+		{"Type", "Key", "Status", "Progress"},
+	}
+	for _, e := range sum.Epics {
+		tableData = append(tableData, statusRow("Epic", e.Key, e.Status, false, ui.ProgressBar(e.Done, e.Total)))
+		for _, st := range e.Stories {
+			tableData = append(tableData, statusRow("Story", "  "+st.Key, st.Value, st.Key == next, ""))
+		}
+		if r := e.Retrospective; r != nil {
+			tableData = append(tableData, statusRow("Retrospective", "  "+r.Key, r.Value, r.Key == next, ""))
+		}
+	}
+
+	if len(tableData) == 1 {
+		pterm.Info.Println("No entries match the filters")
+	} else {
+		pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+	}
+	if len(sum.Ungrouped) > 0 {
+		pterm.Warning.Printf("Not shown: %d development_status entries outside the epic structure; run 'bmad-runner validate' for details\n", len(sum.Ungrouped))
+	}
+	return nil
+}
+
+// statusRow formats one row of the status table; next marks the next work item.
+func statusRow(typ, key string, st status.State, next bool, progress string) []string {
+	if next {
+		key = pterm.Cyan(pterm.Bold.Sprint(key + " ◀ next"))
+	}
+	return []string{typ, key, ui.StatusIcon(st), progress}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/MBFrosty/BMAD-Runner/internal/config"
+	"github.com/MBFrosty/BMAD-Runner/internal/status"
+
+	"github.com/pterm/pterm"
+	"github.com/urfave/cli/v2"
+)
+
+// runValidate is the action for `bmad-runner validate`. It prints each issue as
+// <file>:<line>: <key>: <message> and fails if there are errors (or, with
+// --strict, warnings), so it can gate CI.
+func runValidate(c *cli.Context) error {
+	_, statusPath, err := config.ResolveProjectRoot(c.String("status-file"), c.String("project-root"))
+	if err != nil {
+		return fmt.Errorf("resolving project root: %w", err)
+	}
+	issues, err := status.ValidateFile(statusPath)
+	if err != nil {
+		return err
+	}
+
+	var errorCount, warningCount int
+	for _, issue := range issues {
+		location := statusPath
+		if issue.Line > 0 {
+			location = fmt.Sprintf("%s:%d", statusPath, issue.Line)
+		}
+		msg := issue.Message
+		if issue.Key != "" {
+			msg = issue.Key + ": " + msg
+		}
+		if issue.Severity == status.SeverityError {
+			errorCount++
+			pterm.Error.Printf("%s: %s\n", location, msg)
+		} else {
+			warningCount++
+			pterm.Warning.Printf("%s: %s\n", location, msg)
+		}
+	}
+
+	if errorCount > 0 || (c.Bool("strict") && warningCount > 0) {
+		return fmt.Errorf("%s: %d errors, %d warnings", statusPath, errorCount, warningCount)
+	}
+	if warningCount > 0 {
+		pterm.Success.Printf("%s is valid (%d warnings)\n", statusPath, warningCount)
+		return nil
+	}
+	pterm.Success.Printf("%s is valid\n", statusPath)
+	return nil
+}
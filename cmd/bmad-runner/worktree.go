@@ -0,0 +1,196 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MBFrosty/BMAD-Runner/internal/agent"
+	"github.com/MBFrosty/BMAD-Runner/internal/gitops"
+	"github.com/MBFrosty/BMAD-Runner/internal/status"
+
+	"github.com/pterm/pterm"
+)
+
+// run auto --worktree modes.
+const (
+	worktreeMerge = "merge" // merge the story branch back, fast-forwarding when possible
+	worktreeKeep  = "keep"  // leave the story branch for human review
+)
+
+// storyBranchPrefix prefixes the branch each story is developed on with --worktree.
+const storyBranchPrefix = "bmad/"
+
+// storyWorktree is the git worktree a story's phases run in with --worktree. The
+// agent edits the worktree's copy of sprint-status.yaml; reconcile carries its
+// changes over to the main tree, which stays the one the auto loop reads.
+type storyWorktree struct {
+	repo        *gitops.Repo
+	mode        string
+	story       string
+	branch      string
+	dir         string // worktree top level
+	projectRoot string // project root inside the worktree
+	statusPath  string // sprint-status.yaml inside the worktree
+	statusRel   string // statusPath relative to dir
+	mainStatus  string // sprint-status.yaml in the main tree
+
+	// writer applies changes to the main tree; nil outside --parallel.
+	writer *statusWriter
+
+	// base holds the worktree's entries as of the last reconcile.
+	base map[string]status.State
+}
+
+// openWorktreeRepo checks the --worktree mode and returns the repository holding
+// projectRoot, or nil when --worktree is not set.
+func openWorktreeRepo(mode, projectRoot string) (*gitops.Repo, error) {
+	switch mode {
+	case "":
+		return nil, nil
+	case worktreeMerge, worktreeKeep:
+		return gitops.Open(projectRoot)
+	default:
+		return nil, fmt.Errorf("--worktree %s: want %s or %s", mode, worktreeMerge, worktreeKeep)
+	}
+}
+
+// newStoryWorktree creates the worktree and branch bmad/<story> from HEAD, or
+// reuses them if a previous run of the story left them, and copies the main
+// tree's sprint-status.yaml into it. Changes to the main tree go through writer.
+func newStoryWorktree(repo *gitops.Repo, mode, projectRoot, statusPath, story string, writer *statusWriter) (*storyWorktree, error) {
+	projectRel, err := repoRelative(repo, projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	statusRel, err := repoRelative(repo, statusPath)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := repo.WorktreeDir(story)
+	if err != nil {
+		return nil, err
+	}
+	w := &storyWorktree{
+		repo:        repo,
+		mode:        mode,
+		story:       story,
+		branch:      storyBranchPrefix + story,
+		dir:         dir,
+		projectRoot: filepath.Join(dir, projectRel),
+		statusPath:  filepath.Join(dir, statusRel),
+		statusRel:   statusRel,
+		mainStatus:  statusPath,
+		writer:      writer,
+	}
+	if err := writer.do(func() error { return repo.AddWorktree(dir, w.branch) }); err != nil {
+		return nil, fmt.Errorf("creating worktree for %s: %w", story, err)
+	}
+
+	data, err := os.ReadFile(statusPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading status file: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(w.statusPath), 0o755); err != nil {
+		return nil, fmt.Errorf("copying status file to worktree: %w", err)
+	}
+	if err := status.WriteFileAtomic(w.statusPath, data); err != nil {
+		return nil, err
+	}
+	s, err := status.ParseBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing status file: %w", err)
+	}
+	w.base = s.DevStatus
+	return w, nil
+}
+
+// repoRelative returns path relative to the repository root; it must be inside it.
+func repoRelative(repo *gitops.Repo, path string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(repo.Root, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the git repository %s", path, repo.Root)
+	}
+	return rel, nil
+}
+
+// runner returns a copy of r that runs in the worktree.
+func (w *storyWorktree) runner(r *agent.Runner) *agent.Runner {
+	wr := *r
+	wr.ProjectRoot = w.projectRoot
+	wr.StatusFile = w.statusPath
+	return &wr
+}
+
+// reconcile copies the sprint-status entries the agent changed in the worktree
+// since the last reconcile into the main tree's sprint-status.yaml. Entries the
+// agent added are not copied.
+func (w *storyWorktree) reconcile() error {
+	s, err := status.Parse(w.statusPath)
+	if err != nil {
+		return fmt.Errorf("worktree status file: %w", err)
+	}
+	for _, e := range s.OrderedEntries {
+		old, ok := w.base[e.Key]
+		if !ok {
+			pterm.Warning.Printf("New entry %s in the worktree's sprint-status.yaml is not copied to the main tree\n", e.Key)
+			w.base[e.Key] = e.Value
+			continue
+		}
+		if old == e.Value {
+			continue
+		}
+		if err := w.writer.setStatus(w.mainStatus, e.Key, e.Value); err != nil {
+			return err
+		}
+		w.base[e.Key] = e.Value
+	}
+	return nil
+}
+
+// finish commits the story's changes (other than sprint-status.yaml, already
+// reconciled) on its branch and removes the worktree. With merge mode the branch
+// is then merged into the main tree and deleted; with keep mode it is left for
+// review. A branch that does not merge cleanly is left in place.
+func (w *storyWorktree) finish() error {
+	if err := w.reconcile(); err != nil {
+		return err
+	}
+	if _, err := gitops.CommitAll(w.dir, "bmad-runner: "+w.story, w.statusRel); err != nil {
+		return fmt.Errorf("committing story %s: %w", w.story, err)
+	}
+	if err := w.writer.do(func() error { return w.repo.RemoveWorktree(w.dir) }); err != nil {
+		return err
+	}
+	if w.mode == worktreeKeep {
+		pterm.Info.Printf("Branch %s left for review\n", w.branch)
+		return nil
+	}
+
+	var ff bool
+	err := w.writer.do(func() error {
+		var err error
+		if ff, err = w.repo.Merge(w.branch); err != nil {
+			return err
+		}
+		return w.repo.DeleteBranch(w.branch)
+	})
+	if errors.Is(err, gitops.ErrMergeConflict) {
+		return fmt.Errorf("story %s is done but its branch %s does not merge cleanly; merge it by hand and run again: %w", w.story, w.branch, err)
+	}
+	if err != nil {
+		return err
+	}
+	if ff {
+		pterm.Success.Printf("Fast-forwarded %s into the main tree\n", w.branch)
+	} else {
+		pterm.Success.Printf("Merged %s into the main tree\n", w.branch)
+	}
+	return nil
+}
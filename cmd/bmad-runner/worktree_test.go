@@ -0,0 +1,100 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MBFrosty/BMAD-Runner/internal/gitops"
+	"github.com/MBFrosty/BMAD-Runner/internal/status"
+)
+
+const testSprintStatus = `development_status:
+  epic-1: in-progress
+  1-1-a: ready-for-dev
+  epic-1-retrospective: optional
+`
+
+// After a worktree phase with --commit, the phase's work is committed on the
+// story branch and the reconciled sprint-status.yaml in the main tree, leaving
+// both trees clean.
+func TestCommitWorktreePhase(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.name", "test"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "commit.gpgsign", "false"},
+	} {
+		if _, err := gitops.Git(root, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	statusPath := filepath.Join(root, "sprint-status.yaml")
+	if err := os.WriteFile(statusPath, []byte(testSprintStatus), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gitops.CommitAll(root, "initial"); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := gitops.Open(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exclude, err := runnerFiles(repo, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	committer := &phaseCommitter{repo: repo, exclude: exclude}
+
+	writer := newStatusWriter()
+	defer writer.close()
+	wt, err := newStoryWorktree(repo, worktreeKeep, root, statusPath, "1-1-a", writer)
+	if err != nil {
+		t.Fatalf("newStoryWorktree: %v", err)
+	}
+
+	// The agent's dev-story run: code in the worktree and a status change.
+	if err := os.WriteFile(filepath.Join(wt.dir, "login.go"), []byte("package auth\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := status.SetStatus(wt.statusPath, "1-1-a", status.StateReview); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.reconcile(); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if err := committer.commitWorktree(wt, "1-1-a", "dev-story", "claude-code", "sonnet", time.Minute); err != nil {
+		t.Fatalf("commitWorktree: %v", err)
+	}
+
+	if changes, err := gitops.Changes(root, exclude...); err != nil || changes != nil {
+		t.Errorf("main tree changes after the phase = %q, %v; want none", changes, err)
+	}
+	if changes, err := gitops.Changes(wt.dir, wt.statusRel); err != nil || changes != nil {
+		t.Errorf("worktree changes after the phase = %q, %v; want none", changes, err)
+	}
+	msg, err := gitops.Git(root, "log", "-1", "--format=%B", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(msg, "bmad(1-1-a): dev-story (sprint status)") || !strings.Contains(msg, "BMAD-Phase: dev-story") {
+		t.Errorf("main tree commit message = %q, want the sprint status commit with trailers", msg)
+	}
+	if out, err := gitops.Git(root, "show", "--name-only", "--format=", "main"); err != nil || out != "sprint-status.yaml" {
+		t.Errorf("main tree commit files = %q, %v; want sprint-status.yaml only", out, err)
+	}
+	if out, err := gitops.Git(root, "show", "--name-only", "--format=", wt.branch); err != nil || out != "login.go" {
+		t.Errorf("story branch commit files = %q, %v; want login.go only", out, err)
+	}
+}
@@ -0,0 +1,104 @@
+package agent
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/MBFrosty/BMAD-Runner/internal/config"
+)
+
+// AgentBackend describes one agent CLI: how to find it, how to invoke it, where its
+// BMAD command files live and how to read its output. Each backend lives in its
+// own file and registers itself with RegisterBackend from an init function.
+type AgentBackend interface {
+	// Name is the --agent-type value that selects the backend.
+	Name() string
+	// BinaryNames are the executables to look for in PATH, in order.
+	BinaryNames() []string
+	// Args returns the command-line arguments for one phase run.
+	Args(inv Invocation) []string
+	// CommandDir is the project-relative directory holding the backend's
+	// bmad-bmm-<phase>.md command files; .cursor/commands is the fallback.
+	CommandDir() string
+	// NewDecoder returns a fresh decoder for the backend's structured stdout, or
+	// nil if the backend prints plain text.
+	NewDecoder() LineDecoder
+	// Capabilities reports optional features of the backend.
+	Capabilities() Capabilities
+	// DefaultModels maps workflow phases to models, with the "default" and
+	// "fallback" keys described on config.DefaultModels. Each built-in backend
+	// keeps its table in its own file.
+	DefaultModels() map[string]string
+}
+
+// Invocation is what a backend needs to build its command line.
+type Invocation struct {
+	Prompt      string
+	Model       string
+	ProjectRoot string
+	// PromptFile holds the prompt, for backends with Capabilities.PromptFile.
+	PromptFile string
+	// UsePTY is set when the agent will run in a pseudo-terminal (only for
+	// backends with Capabilities.PTYFallback), so structured output should be off.
+	UsePTY bool
+}
+
+// Capabilities are optional backend features the runner adapts to.
+type Capabilities struct {
+	// StructuredOutput means stdout is JSONL events for NewDecoder.
+	StructuredOutput bool
+	// TokenUsage means the decoder reports token usage.
+	TokenUsage bool
+	// PTYFallback means the backend can instead run in a PTY with plain text
+	// output (--pty), for agent versions without structured output.
+	PTYFallback bool
+	// PromptFile means Args reads Invocation.PromptFile, so the runner writes
+	// the prompt to a temporary file for the duration of the run.
+	PromptFile bool
+}
+
+// DefaultBackend is used when no agent type is given.
+const DefaultBackend = config.AgentTypeCursorAgent
+
+var (
+	backendsMu sync.RWMutex
+	backends   = map[string]AgentBackend{}
+)
+
+// RegisterBackend makes b selectable by name, replacing any backend with the same
+// name, and registers its binaries and default models with the config package.
+func RegisterBackend(b AgentBackend) {
+	backendsMu.Lock()
+	backends[b.Name()] = b
+	backendsMu.Unlock()
+	config.RegisterAgent(b.Name(), b.BinaryNames(), b.DefaultModels())
+}
+
+// LookupBackend returns the backend registered as name.
+func LookupBackend(name string) (AgentBackend, bool) {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+	b, ok := backends[name]
+	return b, ok
+}
+
+// BackendFor returns the backend registered as name, or the default backend.
+func BackendFor(name string) AgentBackend {
+	if b, ok := LookupBackend(name); ok {
+		return b
+	}
+	b, _ := LookupBackend(DefaultBackend)
+	return b
+}
+
+// BackendNames returns the registered backend names, sorted.
+func BackendNames() []string {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,171 @@
+package agent
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/MBFrosty/BMAD-Runner/internal/config"
+)
+
+func TestBuiltinBackends(t *testing.T) {
+	t.Parallel()
+	inv := Invocation{Prompt: "do it", Model: "m1", ProjectRoot: "/work"}
+	tests := []struct {
+		name       string
+		commandDir string
+		args       []string
+	}{
+		{config.AgentTypeCursorAgent, ".cursor/commands", []string{"-p", "--output-format", "stream-json", "-f", "--approve-mcps", "--model", "m1", "--workspace", "/work", "do it"}},
+		{config.AgentTypeClaudeCode, ".claude/commands", []string{"-p", "--output-format", "stream-json", "--verbose", "--model", "m1", "--dangerously-skip-permissions", "do it"}},
+		{config.AgentTypeGeminiCLI, ".cursor/commands", []string{"--approval-mode", "yolo", "--model", "m1", "--output-format", "stream-json", "-p", "do it"}},
+		{config.AgentTypeOpenCode, ".opencode/commands", []string{"run", "--model", "m1", "--format", "json", "do it"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			b, ok := LookupBackend(tt.name)
+			if !ok {
+				t.Fatalf("backend %q not registered", tt.name)
+			}
+			if got := b.Args(inv); !reflect.DeepEqual(got, tt.args) {
+				t.Errorf("Args = %q, want %q", got, tt.args)
+			}
+			if got := b.CommandDir(); got != tt.commandDir {
+				t.Errorf("CommandDir = %q, want %q", got, tt.commandDir)
+			}
+			if len(b.BinaryNames()) == 0 || b.DefaultModels()["default"] == "" {
+				t.Errorf("backend %q has no binaries or default model", tt.name)
+			}
+			if b.Capabilities().StructuredOutput && b.NewDecoder() == nil {
+				t.Errorf("backend %q reports structured output but has no decoder", tt.name)
+			}
+		})
+	}
+}
+
+// The built-in backends register their model tables with config.
+func TestBuiltinDefaultModels(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		agentType string
+		phase     string
+		want      string
+		fallback  string
+	}{
+		{config.AgentTypeCursorAgent, "create-story", "claude-4.6-sonnet-medium", "composer-1.5"},
+		{config.AgentTypeCursorAgent, "dev-story", "composer-1.5", "claude-4.6-sonnet-medium"},
+		{config.AgentTypeCursorAgent, "nonexistent-phase", "composer-1.5", "claude-4.6-sonnet-medium"},
+		{config.AgentTypeClaudeCode, "dev-story", "haiku", "sonnet"},
+		{config.AgentTypeClaudeCode, "code-review", "sonnet", "haiku"},
+		{config.AgentTypeGeminiCLI, "code-review", "gemini-3-pro", "gemini-3-flash"},
+		{config.AgentTypeOpenCode, "create-story", "opencode-go/kimi-k2.5", "opencode-go/glm-5"},
+		{config.AgentTypeOpenCode, "dev-story", "opencode-go/minimax-m2.5", "opencode-go/glm-5"},
+		{config.AgentTypeOpenCode, "correct-course", "opencode-go/glm-5", "opencode-go/kimi-k2.5"},
+		{"unknown-agent", "create-story", "claude-4.6-sonnet-medium", "composer-1.5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.agentType+"_"+tt.phase, func(t *testing.T) {
+			t.Parallel()
+			got := config.DefaultModel(tt.agentType, tt.phase)
+			if got != tt.want {
+				t.Errorf("config.DefaultModel(%q, %q) = %q, want %q", tt.agentType, tt.phase, got, tt.want)
+			}
+			if fb := config.FallbackModel(tt.agentType, got); fb != tt.fallback {
+				t.Errorf("config.FallbackModel(%q, %q) = %q, want %q", tt.agentType, got, fb, tt.fallback)
+			}
+		})
+	}
+}
+
+func TestGeminiPTYArgs(t *testing.T) {
+	t.Parallel()
+	args := BackendFor(config.AgentTypeGeminiCLI).Args(Invocation{Prompt: "p", Model: "m", UsePTY: true})
+	if slices.Contains(args, "--output-format") {
+		t.Errorf("PTY args %q should not request structured output", args)
+	}
+}
+
+type fakeBackend struct{}
+
+func (fakeBackend) Name() string                 { return "fake-agent" }
+func (fakeBackend) BinaryNames() []string        { return []string{"fake-agent-bin"} }
+func (fakeBackend) Args(inv Invocation) []string { return []string{inv.Prompt} }
+func (fakeBackend) CommandDir() string           { return ".fake/commands" }
+func (fakeBackend) NewDecoder() LineDecoder      { return nil }
+func (fakeBackend) Capabilities() Capabilities   { return Capabilities{} }
+func (fakeBackend) DefaultModels() map[string]string {
+	return map[string]string{"default": "fake-model", "dev-story": "fake-dev"}
+}
+
+func TestRegisterBackend(t *testing.T) {
+	RegisterBackend(fakeBackend{})
+
+	if !slices.Contains(BackendNames(), "fake-agent") {
+		t.Errorf("BackendNames() = %q, want fake-agent included", BackendNames())
+	}
+	if got := BackendFor("fake-agent").CommandDir(); got != ".fake/commands" {
+		t.Errorf("BackendFor(fake-agent).CommandDir() = %q", got)
+	}
+	if got := BackendFor("no-such-agent").Name(); got != DefaultBackend {
+		t.Errorf("BackendFor(unknown) = %q, want %q", got, DefaultBackend)
+	}
+	if got := config.DefaultModel("fake-agent", "dev-story"); got != "fake-dev" {
+		t.Errorf("config.DefaultModel(fake-agent, dev-story) = %q, want fake-dev", got)
+	}
+	if _, err := config.LookupAgent("", "fake-agent"); err == nil || !strings.Contains(err.Error(), "fake-agent-bin") {
+		t.Errorf("config.LookupAgent(fake-agent) error = %v, want mention of fake-agent-bin", err)
+	}
+	if got := NewRecordFormatter("fake-agent").Format(TranscriptRecord{Type: RecordOutput, Event: []byte(`{"a":1}`)}); !reflect.DeepEqual(got, []string{`{"a":1}`}) {
+		t.Errorf("plain-text backend formats events as %q, want raw JSON", got)
+	}
+}
+
+func TestCustomBackend(t *testing.T) {
+	t.Parallel()
+	b := customBackend{spec: config.BackendSpec{
+		Name:   "wrapper",
+		Binary: "wrap",
+		Args:   []string{"--model={model}", "--cwd", "{workspace}", "--prompt-file", "{prompt_file}"},
+		Output: config.OutputSpec{Format: config.OutputJSON, Text: "msg.content.0.text", Tool: "call.name", ToolInput: "call.args", Error: "error.message"},
+		Models: map[string]string{"default": "m0"},
+	}}
+
+	got := b.Args(Invocation{Prompt: "p", Model: "m1", ProjectRoot: "/work", PromptFile: "/tmp/p.md"})
+	want := []string{"--model=m1", "--cwd", "/work", "--prompt-file", "/tmp/p.md"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Args = %q, want %q", got, want)
+	}
+	if caps := b.Capabilities(); !caps.PromptFile || !caps.StructuredOutput {
+		t.Errorf("Capabilities = %+v, want PromptFile and StructuredOutput", caps)
+	}
+	if got := b.CommandDir(); got != ".cursor/commands" {
+		t.Errorf("CommandDir = %q, want default .cursor/commands", got)
+	}
+
+	dec := b.NewDecoder()
+	events := []struct {
+		raw  string
+		want []string
+	}{
+		{`{"msg":{"content":[{"text":"Looking around\nReading the story"}]}}`, []string{"Reading the story"}},
+		{`{"call":{"name":"Read","args":{"file_path":"a.go"}}}`, []string{"Reading a.go"}},
+		{`{"error":{"message":"quota exceeded"}}`, []string{"Error: quota exceeded"}},
+		{`{"msg":{"content":[]}}`, nil},
+		{`not json`, nil},
+	}
+	for _, ev := range events {
+		if got := dec.Decode([]byte(ev.raw)); !reflect.DeepEqual(got, ev.want) {
+			t.Errorf("Decode(%s) = %q, want %q", ev.raw, got, ev.want)
+		}
+	}
+}
+
+func TestRegisterCustomBackendsRejectsBuiltins(t *testing.T) {
+	t.Parallel()
+	err := RegisterCustomBackends([]config.BackendSpec{{Name: config.AgentTypeClaudeCode, Binary: "x", Args: []string{"{prompt}"}}})
+	if err == nil || !strings.Contains(err.Error(), "built in") {
+		t.Errorf("RegisterCustomBackends(claude-code) error = %v, want built-in error", err)
+	}
+}
@@ -0,0 +1,110 @@
+package agent
+
+import (
+	"encoding/json"
+
+	"github.com/MBFrosty/BMAD-Runner/internal/config"
+)
+
+func init() { RegisterBackend(claudeBackend{}) }
+
+// claudeBackend runs Claude Code (`claude -p`) with stream-json output.
+type claudeBackend struct{}
+
+func (claudeBackend) Name() string {
+	return config.AgentTypeClaudeCode
+}
+
+func (claudeBackend) BinaryNames() []string {
+	return []string{"claude"}
+}
+
+func (claudeBackend) CommandDir() string {
+	return ".claude/commands"
+}
+
+func (claudeBackend) NewDecoder() LineDecoder {
+	return claudeDecoder{}
+}
+
+func (claudeBackend) Capabilities() Capabilities {
+	return Capabilities{StructuredOutput: true}
+}
+
+// claudeModels are Claude Code's default models per workflow phase.
+var claudeModels = map[string]string{
+	"create-story":    "sonnet",
+	"dev-story":       "haiku",
+	"code-review":     "sonnet",
+	"retrospective":   "sonnet",
+	"correct-course":  "sonnet",
+	"sprint-planning": "sonnet",
+	"default":         "sonnet",
+	"fallback":        "haiku",
+}
+
+func (claudeBackend) DefaultModels() map[string]string {
+	return claudeModels
+}
+
+func (claudeBackend) Args(inv Invocation) []string {
+	return []string{
+		"-p",
+		"--output-format", "stream-json",
+		"--verbose",
+		"--model", inv.Model,
+		"--dangerously-skip-permissions",
+		inv.Prompt,
+	}
+}
+
+// --- claude-code stream-json parsing ---
+
+type claudeEvent struct {
+	Type    string     `json:"type"`
+	Message *claudeMsg `json:"message,omitempty"`
+}
+
+type claudeMsg struct {
+	Content []claudeBlock `json:"content"`
+}
+
+type claudeBlock struct {
+	Type  string          `json:"type"` // "text" or "tool_use"
+	Text  string          `json:"text,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+}
+
+// claudeDecoder decodes claude-code style --output-format stream-json events.
+type claudeDecoder struct{}
+
+func (claudeDecoder) Decode(raw []byte) []string {
+	var ev claudeEvent
+	if err := json.Unmarshal(raw, &ev); err != nil {
+		return nil
+	}
+	return extractClaudeStatus(&ev)
+}
+
+func extractClaudeStatus(ev *claudeEvent) []string {
+	if ev.Type != "assistant" || ev.Message == nil {
+		return nil
+	}
+	var lines []string
+	for _, block := range ev.Message.Content {
+		switch block.Type {
+		case "tool_use":
+			lines = append(lines, formatToolUse(block))
+		case "text":
+			if last := lastNonEmptyLine(block.Text); last != "" {
+				lines = append(lines, last)
+			}
+		}
+	}
+	return lines
+}
+
+func formatToolUse(block claudeBlock) string {
+	return formatTool(block.Name, block.Input)
+}
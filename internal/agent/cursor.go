@@ -0,0 +1,226 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MBFrosty/BMAD-Runner/internal/config"
+)
+
+func init() { RegisterBackend(cursorBackend{}) }
+
+// cursorBackend runs Cursor's CLI agent (`cursor-agent -p`, also installed as `agent`).
+type cursorBackend struct{}
+
+func (cursorBackend) Name() string {
+	return config.AgentTypeCursorAgent
+}
+
+func (cursorBackend) BinaryNames() []string {
+	return []string{"cursor-agent", "agent"}
+}
+
+func (cursorBackend) CommandDir() string {
+	return ".cursor/commands"
+}
+
+func (cursorBackend) NewDecoder() LineDecoder {
+	return &cursorDecoder{}
+}
+
+func (cursorBackend) Capabilities() Capabilities {
+	return Capabilities{StructuredOutput: true}
+}
+
+// cursorModels are cursor-agent's default models per workflow phase.
+var cursorModels = map[string]string{
+	"create-story":    "claude-4.6-sonnet-medium",
+	"dev-story":       "composer-1.5",
+	"code-review":     "gemini-3-flash",
+	"retrospective":   "gemini-3-flash",
+	"correct-course":  "claude-4.6-sonnet-medium",
+	"sprint-planning": "claude-4.6-sonnet-medium",
+	"default":         "composer-1.5",
+	"fallback":        "claude-4.6-sonnet-medium",
+}
+
+func (cursorBackend) DefaultModels() map[string]string {
+	return cursorModels
+}
+
+func (cursorBackend) Args(inv Invocation) []string {
+	return []string{
+		"-p",
+		"--output-format", "stream-json",
+		"-f",
+		"--approve-mcps",
+		"--model", inv.Model,
+		"--workspace", inv.ProjectRoot,
+		inv.Prompt,
+	}
+}
+
+// --- cursor-agent stream-json parsing ---
+
+// cursorEvent is one line of cursor-agent --output-format stream-json output.
+// Assistant messages share claude-code's shape; tool calls, thinking and the
+// final result use cursor-specific event types.
+type cursorEvent struct {
+	Type     string                     `json:"type"`    // "system", "user", "assistant", "thinking", "tool_call", "result"
+	Subtype  string                     `json:"subtype"` // "init"; "delta"/"completed"; "started"/"completed"; "success"/"error"
+	Model    string                     `json:"model,omitempty"`
+	Message  *claudeMsg                 `json:"message,omitempty"`
+	ToolCall map[string]json.RawMessage `json:"tool_call,omitempty"`
+
+	// Result events.
+	IsError    bool   `json:"is_error,omitempty"`
+	Result     string `json:"result,omitempty"`
+	DurationMS int64  `json:"duration_ms,omitempty"`
+}
+
+// cursorToolCall is the body of a tool_call entry such as {"readToolCall": {...}}.
+type cursorToolCall struct {
+	Args   json.RawMessage `json:"args"`
+	Result *struct {
+		Success  json.RawMessage `json:"success,omitempty"`
+		Error    json.RawMessage `json:"error,omitempty"`
+		Rejected json.RawMessage `json:"rejected,omitempty"`
+	} `json:"result,omitempty"`
+
+	// Generic "function" tool calls.
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments,omitempty"`
+}
+
+// cursorToolResult is the union of the success payloads summarised on completion.
+type cursorToolResult struct {
+	Path         string `json:"path"`
+	LinesAdded   int    `json:"linesAdded"`
+	LinesRemoved int    `json:"linesRemoved"`
+	LinesCreated int    `json:"linesCreated"`
+	ExitCode     *int   `json:"exitCode"`
+	TotalMatches *int   `json:"totalMatches"`
+}
+
+// cursorToolNames maps cursor-agent tool_call keys to the names formatTool knows.
+var cursorToolNames = map[string]string{
+	"readToolCall":        "read",
+	"editToolCall":        "edit",
+	"writeToolCall":       "write",
+	"deleteToolCall":      "delete",
+	"shellToolCall":       "shell",
+	"grepToolCall":        "grep",
+	"globToolCall":        "glob",
+	"lsToolCall":          "ls",
+	"updateTodosToolCall": "todowrite",
+	"webSearchToolCall":   "websearch",
+}
+
+// cursorDecoder decodes cursor-agent stream-json events. It tracks whether the
+// model is thinking so a run of thinking deltas is shown once.
+type cursorDecoder struct {
+	thinking bool
+}
+
+func (d *cursorDecoder) Decode(raw []byte) []string {
+	var ev cursorEvent
+	if err := json.Unmarshal(raw, &ev); err != nil {
+		return nil
+	}
+	if ev.Type != "thinking" {
+		d.thinking = false
+	}
+	switch ev.Type {
+	case "system":
+		if ev.Subtype == "init" && ev.Model != "" {
+			return []string{"Session started (" + ev.Model + ")"}
+		}
+	case "assistant":
+		return extractClaudeStatus(&claudeEvent{Type: ev.Type, Message: ev.Message})
+	case "thinking":
+		if ev.Subtype == "completed" {
+			d.thinking = false
+		} else if !d.thinking {
+			d.thinking = true
+			return []string{"Thinking..."}
+		}
+	case "tool_call":
+		return formatCursorToolCall(ev.Subtype, ev.ToolCall)
+	case "result":
+		if ev.IsError {
+			if last := lastNonEmptyLine(ev.Result); last != "" {
+				return []string{"Failed: " + last}
+			}
+			return []string{"Failed"}
+		}
+		return []string{fmt.Sprintf("Done in %s", (time.Duration(ev.DurationMS) * time.Millisecond).Round(time.Second))}
+	}
+	return nil
+}
+
+// formatCursorToolCall shows a started tool call like the live display's other tool
+// lines, and a completed one only when its result says something new: edit and
+// write sizes, non-zero shell exits, search match counts, and failures.
+func formatCursorToolCall(subtype string, calls map[string]json.RawMessage) []string {
+	var lines []string
+	for _, key := range slices.Sorted(maps.Keys(calls)) {
+		var call cursorToolCall
+		if err := json.Unmarshal(calls[key], &call); err != nil {
+			continue
+		}
+		name, ok := cursorToolNames[key]
+		args := call.Args
+		if !ok {
+			name = strings.TrimSuffix(key, "ToolCall")
+			if key == "function" && call.Name != "" {
+				name, args = call.Name, json.RawMessage(call.Arguments)
+			}
+		}
+
+		switch subtype {
+		case "started":
+			lines = append(lines, formatTool(name, args))
+		case "completed":
+			if line := formatCursorResult(name, args, &call); line != "" {
+				lines = append(lines, line)
+			}
+		}
+	}
+	return lines
+}
+
+func formatCursorResult(name string, args json.RawMessage, call *cursorToolCall) string {
+	if call.Result == nil {
+		return ""
+	}
+	if call.Result.Error != nil || call.Result.Rejected != nil {
+		return formatTool(name, args) + " (failed)"
+	}
+	var res cursorToolResult
+	if call.Result.Success == nil || json.Unmarshal(call.Result.Success, &res) != nil {
+		return ""
+	}
+	switch name {
+	case "edit":
+		if res.Path != "" {
+			return fmt.Sprintf("Edited %s (+%d -%d)", res.Path, res.LinesAdded, res.LinesRemoved)
+		}
+	case "write":
+		if res.Path != "" {
+			return fmt.Sprintf("Wrote %s (%d lines)", res.Path, res.LinesCreated)
+		}
+	case "shell":
+		if res.ExitCode != nil && *res.ExitCode != 0 {
+			return fmt.Sprintf("Command exited with status %d", *res.ExitCode)
+		}
+	case "grep":
+		if res.TotalMatches != nil {
+			return fmt.Sprintf("Found %d matches", *res.TotalMatches)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,154 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/MBFrosty/BMAD-Runner/internal/config"
+)
+
+// customBackend is a backend declared in a runner config file (config.BackendSpec).
+type customBackend struct {
+	spec config.BackendSpec
+}
+
+// RegisterCustomBackends registers the backends declared in a config file.
+// Built-in backends cannot be redefined.
+func RegisterCustomBackends(specs []config.BackendSpec) error {
+	for _, spec := range specs {
+		if err := spec.Validate(); err != nil {
+			return err
+		}
+		if existing, ok := LookupBackend(spec.Name); ok {
+			if _, custom := existing.(customBackend); !custom {
+				return fmt.Errorf("backend %q is built in and cannot be redefined", spec.Name)
+			}
+		}
+		RegisterBackend(customBackend{spec: spec})
+	}
+	return nil
+}
+
+func (b customBackend) Name() string {
+	return b.spec.Name
+}
+
+func (b customBackend) BinaryNames() []string {
+	return []string{b.spec.Binary}
+}
+
+func (b customBackend) CommandDir() string {
+	if b.spec.CommandDir == "" {
+		return ".cursor/commands"
+	}
+	return b.spec.CommandDir
+}
+
+func (b customBackend) NewDecoder() LineDecoder {
+	switch b.spec.Output.Format {
+	case config.OutputClaudeStreamJSON:
+		return claudeDecoder{}
+	case config.OutputJSON:
+		return jsonPathDecoder{spec: b.spec.Output}
+	default:
+		return nil
+	}
+}
+
+func (b customBackend) Capabilities() Capabilities {
+	format := b.spec.Output.Format
+	return Capabilities{
+		StructuredOutput: format == config.OutputClaudeStreamJSON || format == config.OutputJSON,
+		PromptFile:       b.spec.ArgsContain("{prompt_file}"),
+	}
+}
+
+func (b customBackend) DefaultModels() map[string]string {
+	if len(b.spec.Models) == 0 {
+		// No models: keep the cursor-agent defaults out of a backend that may not
+		// understand them.
+		return map[string]string{"default": ""}
+	}
+	return b.spec.Models
+}
+
+// Args expands the spec's argv template.
+func (b customBackend) Args(inv Invocation) []string {
+	r := strings.NewReplacer(
+		"{model}", inv.Model,
+		"{prompt}", inv.Prompt,
+		"{prompt_file}", inv.PromptFile,
+		"{workspace}", inv.ProjectRoot,
+	)
+	args := make([]string, len(b.spec.Args))
+	for i, arg := range b.spec.Args {
+		args[i] = r.Replace(arg)
+	}
+	return args
+}
+
+// jsonPathDecoder shows JSONL events through the dot paths of a config.OutputSpec.
+type jsonPathDecoder struct {
+	spec config.OutputSpec
+}
+
+func (d jsonPathDecoder) Decode(raw []byte) []string {
+	var ev any
+	if err := json.Unmarshal(raw, &ev); err != nil {
+		return nil
+	}
+	var lines []string
+	if msg := d.errorOf(ev); msg != "" {
+		lines = append(lines, "Error: "+msg)
+	}
+	if name, ok := lookupPath(ev, d.spec.Tool).(string); ok && name != "" {
+		input, _ := json.Marshal(lookupPath(ev, d.spec.ToolInput))
+		lines = append(lines, formatTool(name, input))
+	}
+	if text, ok := lookupPath(ev, d.spec.Text).(string); ok {
+		if last := lastNonEmptyLine(text); last != "" {
+			lines = append(lines, last)
+		}
+	}
+	return lines
+}
+
+// DecodeError returns the error the event reports at the spec's error path, if
+// any, for failure classification.
+func (d jsonPathDecoder) DecodeError(raw []byte) string {
+	var ev any
+	if err := json.Unmarshal(raw, &ev); err != nil {
+		return ""
+	}
+	return d.errorOf(ev)
+}
+
+func (d jsonPathDecoder) errorOf(ev any) string {
+	msg, _ := lookupPath(ev, d.spec.Error).(string)
+	return msg
+}
+
+// lookupPath follows a dot path ("a.b.0.c") through decoded JSON, indexing arrays
+// by number. It returns nil if path is empty or does not resolve.
+func lookupPath(v any, path string) any {
+	if path == "" {
+		return nil
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]any:
+			v = node[key]
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil
+			}
+			v = node[i]
+		default:
+			return nil
+		}
+	}
+	return v
+}
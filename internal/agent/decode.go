@@ -0,0 +1,129 @@
+package agent
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// LineDecoder turns one line of an agent's structured (JSONL) stdout into status
+// lines for the live display. Decoders may keep state across lines, so each phase
+// run gets a fresh one from AgentBackend.NewDecoder.
+type LineDecoder interface {
+	Decode(raw []byte) []string
+}
+
+// newDecoder returns a fresh stdout decoder for agentType (the default backend's
+// for unknown types), or nil if the backend prints plain text.
+func newDecoder(agentType string) LineDecoder {
+	return BackendFor(agentType).NewDecoder()
+}
+
+// toolInput is the union of the tool-call arguments the live display knows how to
+// summarise. Backends disagree on spelling (file_path vs filePath), so both are accepted.
+type toolInput struct {
+	FilePath    string `json:"file_path"`
+	FilePathAlt string `json:"filePath"`
+	Path        string `json:"path"`
+	Command     string `json:"command"`
+	AbsPath     string `json:"absolute_path"`
+	Pattern     string `json:"pattern"`
+	GlobPattern string `json:"globPattern"`
+	Query       string `json:"query"`
+	URL         string `json:"url"`
+	Description string `json:"description"`
+}
+
+func (in toolInput) file() string {
+	switch {
+	case in.FilePath != "":
+		return in.FilePath
+	case in.FilePathAlt != "":
+		return in.FilePathAlt
+	case in.AbsPath != "":
+		return in.AbsPath
+	default:
+		return in.Path
+	}
+}
+
+// formatTool summarises a tool call as a one-line status, e.g. "Reading main.go" or
+// "Running: go test ./...". Tool names are matched case-insensitively so the same
+// formatting serves every backend; unknown tools are shown as "Tool: <name>".
+func formatTool(name string, rawInput json.RawMessage) string {
+	var in toolInput
+	if len(rawInput) > 0 {
+		_ = json.Unmarshal(rawInput, &in)
+	}
+	switch strings.ToLower(name) {
+	case "read", "read_file":
+		if f := in.file(); f != "" {
+			return "Reading " + f
+		}
+	case "edit", "edit_file", "multiedit", "patch":
+		if f := in.file(); f != "" {
+			return "Editing " + f
+		}
+	case "write", "write_file":
+		if f := in.file(); f != "" {
+			return "Writing " + f
+		}
+	case "delete", "delete_file":
+		if f := in.file(); f != "" {
+			return "Deleting " + f
+		}
+	case "bash", "execute_command", "shell":
+		if in.Command != "" {
+			return "Running: " + truncate(in.Command, 50)
+		}
+	case "glob":
+		if in.Pattern != "" {
+			return "Finding files: " + truncate(in.Pattern, 50)
+		}
+		if in.GlobPattern != "" {
+			return "Finding files: " + truncate(in.GlobPattern, 50)
+		}
+		return "Tool: Glob"
+	case "grep":
+		if in.Pattern != "" {
+			return "Searching: " + truncate(in.Pattern, 50)
+		}
+		return "Tool: Grep"
+	case "list", "ls":
+		if in.Path != "" {
+			return "Listing " + in.Path
+		}
+	case "webfetch":
+		if in.URL != "" {
+			return "Fetching " + truncate(in.URL, 50)
+		}
+	case "websearch", "web_search":
+		if in.Query != "" {
+			return "Searching the web: " + truncate(in.Query, 50)
+		}
+	case "task":
+		if in.Description != "" {
+			return "Subtask: " + truncate(in.Description, 50)
+		}
+	case "todowrite", "todo_write", "todo":
+		return "Updating todo list"
+	}
+	return "Tool: " + name
+}
+
+// truncate shortens s to at most n bytes, marking the cut with "...".
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
+
+func lastNonEmptyLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if l := strings.TrimSpace(lines[i]); l != "" {
+			return l
+		}
+	}
+	return ""
+}
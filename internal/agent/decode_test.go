@@ -0,0 +1,189 @@
+package agent
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// decodeFixture feeds every line of testdata/name through a fresh decoder for agentType.
+func decodeFixture(t *testing.T, agentType, name string) []string {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	dec := newDecoder(agentType)
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, dec.Decode(scanner.Bytes())...)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return lines
+}
+
+func TestDecodeFixtures(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		agentType string
+		fixture   string
+		want      []string
+	}{
+		{
+			agentType: "opencode",
+			fixture:   "opencode-session.jsonl",
+			want: []string{
+				"Step 1 started",
+				"Then I'll look at the existing handlers.",
+				"Reading /work/app/_bmad-output/implementation-artifacts/1-2-user-auth.md",
+				"Searching: func .*Handler",
+				"Step 1 done · 12.4k in / 212 out tokens · $0.0123",
+				"Step 2 started",
+				"Editing /work/app/internal/auth/handler.go",
+				"Running: go test ./internal/auth/... -run TestHandlerRej... (failed)",
+				"Updating todo list",
+				"All acceptance criteria are met; story marked for review.",
+				"Step 2 done · 1.4M in / 1.6k out tokens · $0.0087",
+			},
+		},
+		{
+			agentType: "opencode",
+			fixture:   "opencode-error.jsonl",
+			want: []string{
+				"Step 1 started",
+				"Error: Rate limit exceeded: 429 Too Many Requests",
+				"Error: UnknownError",
+			},
+		},
+		{
+			agentType: "cursor-agent",
+			fixture:   "cursor-session.jsonl",
+			want: []string{
+				"Session started (Composer 1.5)",
+				"Thinking...",
+				"I'll read the story file first.",
+				"Reading _bmad-output/implementation-artifacts/1-2-user-auth.md",
+				"Searching: func Login",
+				"Found 2 matches",
+				"Finding files: **/*_test.go",
+				"Editing internal/auth/login.go",
+				"Edited /work/app/internal/auth/login.go (+12 -3)",
+				"Writing internal/auth/token.go",
+				"Wrote /work/app/internal/auth/token.go (19 lines)",
+				"Running: go test ./internal/auth/...",
+				"Command exited with status 1",
+				"Running: go test ./internal/auth/...",
+				"Listing internal/auth",
+				"Deleting internal/auth/old.go",
+				"Deleting internal/auth/old.go (failed)",
+				"Tool: mcp_linear_create_issue",
+				"Story 1.2 is ready for review.",
+				"Done in 1m23s",
+			},
+		},
+		{
+			agentType: "cursor-agent",
+			fixture:   "cursor-error.jsonl",
+			want: []string{
+				"Session started (Claude 4.6 Sonnet)",
+				"Thinking...",
+				"Reading missing.md",
+				"Reading missing.md (failed)",
+				"Thinking...",
+				"Failed: Error: 429 rate limit exceeded",
+			},
+		},
+		{
+			agentType: "gemini-cli",
+			fixture:   "gemini-session.jsonl",
+			want: []string{
+				"Session started (gemini-3-pro)",
+				"I'll start by reading the story file.",
+				"Then I'll update the handler.",
+				"Reading /work/app/_bmad-output/implementation-artifacts/1-2-user-auth.md",
+				"Searching: func Login",
+				"Editing /work/app/internal/auth/login.go",
+				"Running: go test ./internal/auth/...",
+				"Running: go test ./internal/auth/... (failed)",
+				"Warning: Loop detection: repeated tool call",
+				"Tests pass now.",
+				"Story 1.2 is ready for review.",
+				"Done in 20s · 24.3k in / 1.5k out tokens · 4 tool calls",
+			},
+		},
+		{
+			agentType: "gemini-cli",
+			fixture:   "gemini-error.jsonl",
+			want: []string{
+				"Session started (gemini-3-flash)",
+				"Checking quota",
+				"Error: [API Error: 429 RESOURCE_EXHAUSTED quota exceeded]",
+				"Failed: Quota exceeded for model gemini-3-flash",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			t.Parallel()
+			got := decodeFixture(t, tt.agentType, tt.fixture)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decoded %s:\n got %q\nwant %q", tt.fixture, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatTool(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"Read", `{"file_path":"main.go"}`, "Reading main.go"},
+		{"read", `{"filePath":"main.go"}`, "Reading main.go"},
+		{"Edit", `{"file_path":"a.go"}`, "Editing a.go"},
+		{"Write", `{"file_path":"b.go"}`, "Writing b.go"},
+		{"Bash", `{"command":"ls"}`, "Running: ls"},
+		{"Glob", `{"pattern":"**/*.go"}`, "Finding files: **/*.go"},
+		{"Grep", `{}`, "Tool: Grep"},
+		{"list", `{"path":"internal"}`, "Listing internal"},
+		{"webfetch", `{"url":"https://example.com"}`, "Fetching https://example.com"},
+		{"Read", `{}`, "Tool: Read"},
+		{"Mystery", ``, "Tool: Mystery"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+tt.input, func(t *testing.T) {
+			t.Parallel()
+			if got := formatTool(tt.name, []byte(tt.input)); got != tt.want {
+				t.Errorf("formatTool(%q, %s) = %q, want %q", tt.name, tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// A cursor tool_call event with several calls renders them in a stable order.
+func TestFormatCursorToolCallOrder(t *testing.T) {
+	t.Parallel()
+	calls := map[string]json.RawMessage{
+		"readToolCall":  json.RawMessage(`{"args":{"path":"a.go"}}`),
+		"shellToolCall": json.RawMessage(`{"args":{"command":"go test"}}`),
+		"grepToolCall":  json.RawMessage(`{"args":{"pattern":"func Login"}}`),
+		"lsToolCall":    json.RawMessage(`{"args":{"path":"internal"}}`),
+	}
+	want := []string{"Searching: func Login", "Listing internal", "Reading a.go", "Running: go test"}
+	for i := 0; i < 20; i++ {
+		if got := formatCursorToolCall("started", calls); !reflect.DeepEqual(got, want) {
+			t.Fatalf("formatCursorToolCall() = %q, want %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// FailureKind classifies why a phase failed.
+type FailureKind string
+
+const (
+	FailureRateLimit   FailureKind = "rate-limit"  // provider rate limit or usage quota
+	FailureOverloaded  FailureKind = "overloaded"  // provider overloaded or 5xx
+	FailureNetwork     FailureKind = "network"     // connection reset, DNS, socket errors
+	FailureAuth        FailureKind = "auth"        // not logged in, invalid or expired credentials
+	FailureCrash       FailureKind = "crash"       // agent killed by a signal
+	FailureTimeout     FailureKind = "timeout"     // phase or idle timeout
+	FailureInterrupted FailureKind = "interrupted" // cancelled by the user
+	FailureExit        FailureKind = "exit"        // non-zero exit for an unrecognised reason
+)
+
+// Transient reports whether a failure of this kind is worth retrying as-is.
+// Timeouts are not: the auto loop's stall handling deals with hung phases.
+func (k FailureKind) Transient() bool {
+	switch k {
+	case FailureRateLimit, FailureOverloaded, FailureNetwork, FailureCrash:
+		return true
+	default:
+		return false
+	}
+}
+
+// PhaseError is returned when the agent exits unsuccessfully.
+type PhaseError struct {
+	Phase    string
+	Model    string
+	Kind     FailureKind
+	ExitCode int    // -1 when the agent was killed by a signal
+	Detail   string // the output line that determined Kind, if any
+	Err      error
+}
+
+func (e *PhaseError) Error() string {
+	msg := fmt.Sprintf("agent execution failed for phase %s: %v", e.Phase, e.Err)
+	if e.Detail != "" {
+		msg += fmt.Sprintf(" (%s: %s)", e.Kind, e.Detail)
+	}
+	return msg
+}
+
+func (e *PhaseError) Unwrap() error { return e.Err }
+
+// Classify returns the FailureKind of an error returned by Runner, or "" for nil.
+func Classify(err error) FailureKind {
+	var pe *PhaseError
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrInterrupted):
+		return FailureInterrupted
+	case IsTimeout(err):
+		return FailureTimeout
+	case errors.As(err, &pe):
+		return pe.Kind
+	default:
+		return FailureExit
+	}
+}
+
+func newPhaseError(phase, model string, runErr error, diagnostics []string) *PhaseError {
+	exitCode := -1
+	var exitErr *exec.ExitError
+	if errors.As(runErr, &exitErr) {
+		exitCode = exitErr.ExitCode()
+	}
+	kind, detail := classifyOutput(exitCode, diagnostics)
+	return &PhaseError{
+		Phase:    phase,
+		Model:    model,
+		Kind:     kind,
+		ExitCode: exitCode,
+		Detail:   detail,
+		Err:      runErr,
+	}
+}
+
+// statusCode matches an HTTP status code only where it is given as one: after
+// "status", "HTTP", "error" or "code", as in "API Error: 529" or "HTTP/1.1 502".
+func statusCode(codes string) *regexp.Regexp {
+	return regexp.MustCompile(`\b(?:status(?: code)?|http(?:/[\d.]+)?|error|code)\b\W{0,3}(?:` + codes + `)\b`)
+}
+
+// failurePatterns are matched against the lower-cased diagnostic tail, most
+// specific first. The first matching line, scanning from the end, wins.
+var failurePatterns = []struct {
+	kind     FailureKind
+	patterns []*regexp.Regexp
+}{
+	{FailureRateLimit, []*regexp.Regexp{
+		regexp.MustCompile(`\brate[ _-]?limit`),
+		regexp.MustCompile(`\b(?:too many requests|usage limit|quota exceeded|resource_exhausted)\b`),
+		statusCode("429"),
+	}},
+	{FailureOverloaded, []*regexp.Regexp{
+		regexp.MustCompile(`\b(?:overloaded|service unavailable|bad gateway|internal server error)\b`),
+		statusCode("529|503|502"),
+	}},
+	{FailureAuth, []*regexp.Regexp{
+		regexp.MustCompile(`\b(?:unauthorized|authentication(?:_error| error| failed| required)|invalid api key|api key not valid|not logged in|please log in|login required|expired token|permission denied: api)\b`),
+		statusCode("401"),
+	}},
+	{FailureNetwork, []*regexp.Regexp{
+		regexp.MustCompile(`\b(?:econnreset|econnrefused|etimedout|enotfound|eai_again|socket hang up|network error|fetch failed|connection reset|connection refused|tls handshake)\b`),
+	}},
+}
+
+// classifyOutput derives a FailureKind from the exit code and the diagnostic tail.
+func classifyOutput(exitCode int, diagnostics []string) (FailureKind, string) {
+	for i := len(diagnostics) - 1; i >= 0; i-- {
+		line := strings.ToLower(diagnostics[i])
+		for _, fp := range failurePatterns {
+			for _, p := range fp.patterns {
+				if p.MatchString(line) {
+					return fp.kind, diagnostics[i]
+				}
+			}
+		}
+	}
+	if exitCode < 0 || exitCode > 128 {
+		return FailureCrash, ""
+	}
+	return FailureExit, ""
+}
+
+// streamErrorEvent is the subset of a stream-json event that reports errors.
+type streamErrorEvent struct {
+	Type    string          `json:"type"`
+	IsError bool            `json:"is_error"`
+	Result  string          `json:"result"`
+	Message json.RawMessage `json:"message"`
+	Error   json.RawMessage `json:"error"`
+}
+
+// errorEventDecoder is a LineDecoder that knows where a backend's own events
+// carry errors, such as the output.error path of a custom backend.
+type errorEventDecoder interface {
+	DecodeError(raw []byte) string
+}
+
+// noteDiagnostic pushes line, read from stream, to diag if it may explain a
+// failure: a plain stderr line, a JSON error event or error result, or an error
+// that dec (the stdout decoder, or nil) finds in the event. Other output is the
+// agent's own work, which may well mention "429" or "authentication" without
+// anything having gone wrong.
+func noteDiagnostic(diag *lastLinesBuffer, dec LineDecoder, stream, line string) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" {
+		return
+	}
+	var ev streamErrorEvent
+	if !strings.HasPrefix(trimmed, "{") || json.Unmarshal([]byte(trimmed), &ev) != nil {
+		if stream == "stderr" {
+			diag.push(trimmed)
+		}
+		return
+	}
+	switch {
+	case ev.Type == "result" && ev.IsError:
+		diag.push(ev.Result)
+	case ev.Type == "error":
+		diag.push(trimmed)
+	default:
+		if ed, ok := dec.(errorEventDecoder); ok {
+			if msg := ed.DecodeError([]byte(trimmed)); msg != "" {
+				diag.push(msg)
+			}
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/MBFrosty/BMAD-Runner/internal/config"
+)
+
+func TestClassifyOutput(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		exitCode int
+		lines    []string
+		want     FailureKind
+	}{
+		{"rate limit", 1, []string{"starting", "Error: 429 Too Many Requests"}, FailureRateLimit},
+		{"usage limit", 1, []string{"Claude AI usage limit reached|1760000000"}, FailureRateLimit},
+		{"gemini quota", 1, []string{"RESOURCE_EXHAUSTED: Quota exceeded for model"}, FailureRateLimit},
+		{"overloaded", 1, []string{`API Error: 529 {"type":"overloaded_error"}`}, FailureOverloaded},
+		{"auth", 1, []string{"Error: not logged in. Run `agent login`."}, FailureAuth},
+		{"network", 1, []string{"FetchError: request failed, reason: socket hang up"}, FailureNetwork},
+		{"last matching line wins", 1, []string{"connection reset by peer", "401 Unauthorized"}, FailureAuth},
+		{"killed by signal", -1, nil, FailureCrash},
+		{"shell signal exit code", 137, []string{"something unrelated"}, FailureCrash},
+		{"plain exit", 2, []string{"story file not found"}, FailureExit},
+		{"http status line", 1, []string{"HTTP/1.1 503 Service Unavailable"}, FailureOverloaded},
+		{"status code", 1, []string{"request failed with status code 401"}, FailureAuth},
+		{"line number is not a status", 1, []string{"fixed handler at line 503"}, FailureExit},
+		{"bare codes are not statuses", 1, []string{"retry after 429 ms", "port 5029 in use"}, FailureExit},
+		{"authentication story", 1, []string{"implementing authentication middleware"}, FailureExit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, _ := classifyOutput(tt.exitCode, tt.lines)
+			if got != tt.want {
+				t.Errorf("classifyOutput(%d, %q) = %q, want %q", tt.exitCode, tt.lines, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNoteDiagnostic(t *testing.T) {
+	t.Parallel()
+	diag := &lastLinesBuffer{max: diagLinesMax}
+	noteDiagnostic(diag, nil, "stdout", `{"type":"assistant","message":{"content":[{"type":"text","text":"rate limit docs"}]}}`)
+	noteDiagnostic(diag, nil, "stdout", `{"type":"result","subtype":"success","is_error":false,"result":"all good"}`)
+	noteDiagnostic(diag, nil, "stdout", `{"type":"result","subtype":"error","is_error":true,"result":"API Error: 429"}`)
+	noteDiagnostic(diag, nil, "stdout", "HTTP 503 handler returns 401 on authentication errors")
+	noteDiagnostic(diag, nil, "pty", "Fixed the 429 rate limit test")
+	custom := jsonPathDecoder{spec: config.OutputSpec{Text: "msg.text", Error: "error.message"}}
+	noteDiagnostic(diag, custom, "stdout", `{"kind":"say","msg":{"text":"handled the 401 case"}}`)
+	noteDiagnostic(diag, custom, "stdout", `{"kind":"err","error":{"message":"401 Unauthorized: invalid api key"}}`)
+	noteDiagnostic(diag, nil, "stderr", "npm WARN deprecated")
+	noteDiagnostic(diag, nil, "stderr", "   ")
+
+	got := diag.get()
+	want := []string{"API Error: 429", "401 Unauthorized: invalid api key", "npm WARN deprecated"}
+	if len(got) != len(want) {
+		t.Fatalf("diag = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("diag[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+// Ordinary agent output that mentions status codes or auth must not decide the
+// failure kind: it never reaches the diagnostic tail.
+func TestOrdinaryOutputStaysExit(t *testing.T) {
+	t.Parallel()
+	out := &phaseOutput{preview: &lastLinesBuffer{max: lastLinesMax}, diag: &lastLinesBuffer{max: diagLinesMax}}
+	readPipe(strings.NewReader("Fixed handler at line 503\nHTTP 429 now maps to a rate limit error\nAuthentication failed test added\n"), nil, out, "stdout")
+	readPTY(strings.NewReader("Story: 401 Unauthorized page\r\nConnection reset handling done\r\n"), out)
+
+	if kind, detail := classifyOutput(1, out.diag.get()); kind != FailureExit {
+		t.Errorf("classifyOutput() = %q (from %q), want %q", kind, detail, FailureExit)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		err  error
+		want FailureKind
+	}{
+		{"nil", nil, ""},
+		{"interrupted", fmt.Errorf("phase x: %w", ErrInterrupted), FailureInterrupted},
+		{"idle timeout", fmt.Errorf("phase x: %w", ErrIdleTimeout), FailureTimeout},
+		{"phase error", fmt.Errorf("wrapped: %w", &PhaseError{Kind: FailureOverloaded, Err: errors.New("exit status 1")}), FailureOverloaded},
+		{"other", errors.New("reading command file"), FailureExit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := Classify(tt.err); got != tt.want {
+				t.Errorf("Classify(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDo(t *testing.T) {
+	t.Parallel()
+	transient := &PhaseError{Kind: FailureRateLimit, Err: errors.New("exit status 1")}
+	permanent := &PhaseError{Kind: FailureAuth, Err: errors.New("exit status 1")}
+
+	tests := []struct {
+		name       string
+		policy     RetryPolicy
+		results    []error
+		wantErr    bool
+		wantModels []string
+	}{
+		{
+			name:       "success first try",
+			policy:     RetryPolicy{MaxRetries: 2},
+			results:    []error{nil},
+			wantModels: []string{"m1"},
+		},
+		{
+			name:       "transient then success",
+			policy:     RetryPolicy{MaxRetries: 2},
+			results:    []error{transient, nil},
+			wantModels: []string{"m1", "m1"},
+		},
+		{
+			name:       "permanent not retried",
+			policy:     RetryPolicy{MaxRetries: 2},
+			results:    []error{permanent},
+			wantErr:    true,
+			wantModels: []string{"m1"},
+		},
+		{
+			name:       "timeout not retried",
+			policy:     RetryPolicy{MaxRetries: 2},
+			results:    []error{fmt.Errorf("x: %w", ErrPhaseTimeout)},
+			wantErr:    true,
+			wantModels: []string{"m1"},
+		},
+		{
+			name:       "retries exhausted",
+			policy:     RetryPolicy{MaxRetries: 1},
+			results:    []error{transient, transient},
+			wantErr:    true,
+			wantModels: []string{"m1", "m1"},
+		},
+		{
+			name:       "fallback model after retries",
+			policy:     RetryPolicy{MaxRetries: 1, FallbackModel: "m2"},
+			results:    []error{transient, transient, nil},
+			wantModels: []string{"m1", "m1", "m2"},
+		},
+		{
+			name:       "fallback equal to model is ignored",
+			policy:     RetryPolicy{MaxRetries: 0, FallbackModel: "m1"},
+			results:    []error{transient},
+			wantErr:    true,
+			wantModels: []string{"m1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var models []string
+			err := tt.policy.Do(context.Background(), "dev-story", "m1", func(model string) error {
+				models = append(models, model)
+				return tt.results[len(models)-1]
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Do() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if fmt.Sprint(models) != fmt.Sprint(tt.wantModels) {
+				t.Errorf("models tried = %v, want %v", models, tt.wantModels)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDoCancelledWhileWaiting(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	policy := RetryPolicy{MaxRetries: 3, Backoff: 1 << 40}
+	err := policy.Do(ctx, "dev-story", "m1", func(string) error {
+		return &PhaseError{Kind: FailureNetwork, Err: errors.New("exit status 1")}
+	})
+	if !errors.Is(err, ErrInterrupted) {
+		t.Errorf("Do() error = %v, want ErrInterrupted", err)
+	}
+}
@@ -0,0 +1,220 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MBFrosty/BMAD-Runner/internal/config"
+)
+
+func init() { RegisterBackend(geminiBackend{}) }
+
+// geminiBackend runs gemini-cli in yolo mode. BMAD has no gemini command directory, so
+// it reads the .cursor/commands files.
+type geminiBackend struct{}
+
+func (geminiBackend) Name() string {
+	return config.AgentTypeGeminiCLI
+}
+
+func (geminiBackend) BinaryNames() []string {
+	return []string{"gemini"}
+}
+
+func (geminiBackend) CommandDir() string {
+	return ".cursor/commands"
+}
+
+func (geminiBackend) NewDecoder() LineDecoder {
+	return &geminiDecoder{}
+}
+
+func (geminiBackend) Capabilities() Capabilities {
+	return Capabilities{StructuredOutput: true, TokenUsage: true, PTYFallback: true}
+}
+
+// geminiModels are gemini-cli's default models per workflow phase.
+var geminiModels = map[string]string{
+	"create-story":    "gemini-3-pro",
+	"dev-story":       "gemini-3-flash",
+	"code-review":     "gemini-3-pro",
+	"retrospective":   "gemini-3-pro",
+	"correct-course":  "gemini-3-pro",
+	"sprint-planning": "gemini-3-pro",
+	"default":         "gemini-3-pro",
+	"fallback":        "gemini-3-flash",
+}
+
+func (geminiBackend) DefaultModels() map[string]string {
+	return geminiModels
+}
+
+func (geminiBackend) Args(inv Invocation) []string {
+	args := []string{"--approval-mode", "yolo", "--model", inv.Model}
+	if !inv.UsePTY {
+		args = append(args, "--output-format", "stream-json")
+	}
+	return append(args, "-p", inv.Prompt)
+}
+
+// --- gemini-cli --output-format stream-json parsing ---
+
+// geminiEvent is one line of gemini-cli --output-format stream-json output.
+type geminiEvent struct {
+	Type string `json:"type"` // "init", "message", "tool_use", "tool_result", "error", "result"
+
+	// init
+	Model string `json:"model,omitempty"`
+
+	// message
+	Role    string `json:"role,omitempty"`
+	Content string `json:"content,omitempty"`
+	Delta   bool   `json:"delta,omitempty"`
+
+	// tool_use / tool_result
+	ToolName   string          `json:"tool_name,omitempty"`
+	ToolID     string          `json:"tool_id,omitempty"`
+	Parameters json.RawMessage `json:"parameters,omitempty"`
+
+	// tool_result / result / error
+	Status   string `json:"status,omitempty"`
+	Severity string `json:"severity,omitempty"`
+	Message  string `json:"message,omitempty"`
+	Error    *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+	Stats *struct {
+		InputTokens  int   `json:"input_tokens"`
+		OutputTokens int   `json:"output_tokens"`
+		DurationMS   int64 `json:"duration_ms"`
+		ToolCalls    int   `json:"tool_calls"`
+	} `json:"stats,omitempty"`
+}
+
+// geminiToolNames maps gemini-cli built-in tool names to the names formatTool knows.
+var geminiToolNames = map[string]string{
+	"read_file":           "read",
+	"write_file":          "write",
+	"replace":             "edit",
+	"run_shell_command":   "shell",
+	"glob":                "glob",
+	"search_file_content": "grep",
+	"list_directory":      "ls",
+	"web_fetch":           "webfetch",
+	"google_web_search":   "websearch",
+	"write_todos":         "todowrite",
+}
+
+// geminiDecoder decodes gemini-cli stream-json events. Assistant text arrives in
+// delta chunks that split lines arbitrarily, so it is buffered and shown a
+// complete line at a time; tool calls are remembered until their result arrives.
+type geminiDecoder struct {
+	text  strings.Builder
+	tools map[string]string // tool_id -> formatted tool line
+}
+
+func (d *geminiDecoder) Decode(raw []byte) []string {
+	var ev geminiEvent
+	if err := json.Unmarshal(raw, &ev); err != nil {
+		return nil
+	}
+	if ev.Type == "message" {
+		return d.message(&ev)
+	}
+
+	lines := d.flush()
+	switch ev.Type {
+	case "init":
+		if ev.Model != "" {
+			lines = append(lines, "Session started ("+ev.Model+")")
+		}
+	case "tool_use":
+		name := ev.ToolName
+		if mapped, ok := geminiToolNames[name]; ok {
+			name = mapped
+		}
+		line := formatTool(name, ev.Parameters)
+		if d.tools == nil {
+			d.tools = make(map[string]string)
+		}
+		d.tools[ev.ToolID] = line
+		lines = append(lines, line)
+	case "tool_result":
+		if ev.Status == "error" {
+			line := d.tools[ev.ToolID]
+			if line == "" {
+				line = "Tool"
+			}
+			lines = append(lines, line+" (failed)")
+		}
+		delete(d.tools, ev.ToolID)
+	case "error":
+		msg := ev.Message
+		if msg == "" && ev.Error != nil {
+			msg = ev.Error.Message
+		}
+		if ev.Severity == "warning" {
+			lines = append(lines, "Warning: "+msg)
+		} else {
+			lines = append(lines, "Error: "+msg)
+		}
+	case "result":
+		lines = append(lines, formatGeminiResult(&ev))
+	}
+	return lines
+}
+
+// message handles assistant text, buffering deltas until a line is complete.
+func (d *geminiDecoder) message(ev *geminiEvent) []string {
+	if ev.Role != "assistant" {
+		return nil
+	}
+	if !ev.Delta {
+		lines := d.flush()
+		if last := lastNonEmptyLine(ev.Content); last != "" {
+			lines = append(lines, last)
+		}
+		return lines
+	}
+	d.text.WriteString(ev.Content)
+	buffered := d.text.String()
+	i := strings.LastIndexByte(buffered, '\n')
+	if i < 0 {
+		return nil
+	}
+	d.text.Reset()
+	d.text.WriteString(buffered[i+1:])
+	if last := lastNonEmptyLine(buffered[:i]); last != "" {
+		return []string{last}
+	}
+	return nil
+}
+
+// flush returns any buffered partial line of assistant text.
+func (d *geminiDecoder) flush() []string {
+	rest := strings.TrimSpace(d.text.String())
+	d.text.Reset()
+	if rest == "" {
+		return nil
+	}
+	return []string{lastNonEmptyLine(rest)}
+}
+
+func formatGeminiResult(ev *geminiEvent) string {
+	if ev.Status != "" && ev.Status != "success" {
+		msg := ev.Status
+		if ev.Error != nil && ev.Error.Message != "" {
+			msg = ev.Error.Message
+		}
+		return "Failed: " + msg
+	}
+	if ev.Stats == nil {
+		return "Done"
+	}
+	return fmt.Sprintf("Done in %s · %s in / %s out tokens · %d tool calls",
+		(time.Duration(ev.Stats.DurationMS) * time.Millisecond).Round(time.Second),
+		formatCount(ev.Stats.InputTokens), formatCount(ev.Stats.OutputTokens), ev.Stats.ToolCalls)
+}
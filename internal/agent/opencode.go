@@ -0,0 +1,183 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/MBFrosty/BMAD-Runner/internal/config"
+)
+
+func init() { RegisterBackend(opencodeBackend{}) }
+
+// opencodeBackend runs `opencode run` with JSON event output.
+type opencodeBackend struct{}
+
+func (opencodeBackend) Name() string {
+	return config.AgentTypeOpenCode
+}
+
+func (opencodeBackend) BinaryNames() []string {
+	return []string{"opencode"}
+}
+
+func (opencodeBackend) CommandDir() string {
+	return ".opencode/commands"
+}
+
+func (opencodeBackend) NewDecoder() LineDecoder {
+	return &opencodeDecoder{}
+}
+
+func (opencodeBackend) Capabilities() Capabilities {
+	return Capabilities{StructuredOutput: true, TokenUsage: true}
+}
+
+// opencodeModels are opencode's default models per workflow phase.
+var opencodeModels = map[string]string{
+	"create-story":    "opencode-go/kimi-k2.5",
+	"dev-story":       "opencode-go/minimax-m2.5",
+	"code-review":     "opencode-go/kimi-k2.5",
+	"retrospective":   "opencode-go/kimi-k2.5",
+	"correct-course":  "opencode-go/glm-5",
+	"sprint-planning": "opencode-go/glm-5",
+	"default":         "opencode-go/kimi-k2.5",
+	"fallback":        "opencode-go/glm-5",
+}
+
+func (opencodeBackend) DefaultModels() map[string]string {
+	return opencodeModels
+}
+
+func (opencodeBackend) Args(inv Invocation) []string {
+	return []string{
+		"run",
+		"--model", inv.Model,
+		"--format", "json",
+		inv.Prompt,
+	}
+}
+
+// --- opencode `run --format json` parsing ---
+
+// opencodeEvent is one line of `opencode run --format json` output. Every event
+// carries the message part it reports on; error events carry an error instead.
+type opencodeEvent struct {
+	Type  string         `json:"type"` // "step_start", "text", "tool_use", "step_finish", "error"
+	Part  *opencodePart  `json:"part,omitempty"`
+	Error *opencodeError `json:"error,omitempty"`
+}
+
+type opencodePart struct {
+	Type   string          `json:"type"`
+	Text   string          `json:"text,omitempty"`
+	Tool   string          `json:"tool,omitempty"`
+	State  *opencodeState  `json:"state,omitempty"`
+	Reason string          `json:"reason,omitempty"`
+	Cost   float64         `json:"cost,omitempty"`
+	Tokens *opencodeTokens `json:"tokens,omitempty"`
+}
+
+type opencodeState struct {
+	Status string          `json:"status"` // "pending", "running", "completed", "error"
+	Input  json.RawMessage `json:"input,omitempty"`
+	Title  string          `json:"title,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+type opencodeTokens struct {
+	Input     int `json:"input"`
+	Output    int `json:"output"`
+	Reasoning int `json:"reasoning"`
+	Cache     struct {
+		Read  int `json:"read"`
+		Write int `json:"write"`
+	} `json:"cache"`
+}
+
+type opencodeError struct {
+	Name string `json:"name"`
+	Data struct {
+		Message string `json:"message"`
+	} `json:"data"`
+}
+
+// opencodeDecoder decodes opencode JSON events. It counts steps so step
+// boundaries and their token usage can be shown as "Step N ...".
+type opencodeDecoder struct {
+	step int
+}
+
+func (d *opencodeDecoder) Decode(raw []byte) []string {
+	var ev opencodeEvent
+	if err := json.Unmarshal(raw, &ev); err != nil {
+		return nil
+	}
+	switch ev.Type {
+	case "step_start":
+		d.step++
+		return []string{fmt.Sprintf("Step %d started", d.step)}
+	case "text":
+		if ev.Part != nil {
+			if last := lastNonEmptyLine(ev.Part.Text); last != "" {
+				return []string{last}
+			}
+		}
+	case "tool_use":
+		if ev.Part != nil && ev.Part.Tool != "" {
+			return []string{formatOpencodeTool(ev.Part)}
+		}
+	case "step_finish":
+		if ev.Part != nil {
+			return []string{d.formatStepFinish(ev.Part)}
+		}
+	case "error":
+		if ev.Error != nil {
+			msg := ev.Error.Data.Message
+			if msg == "" {
+				msg = ev.Error.Name
+			}
+			return []string{"Error: " + msg}
+		}
+	}
+	return nil
+}
+
+func formatOpencodeTool(part *opencodePart) string {
+	if part.State == nil {
+		return formatTool(part.Tool, nil)
+	}
+	line := formatTool(part.Tool, part.State.Input)
+	if part.State.Status == "error" {
+		line += " (failed)"
+	}
+	return line
+}
+
+// formatStepFinish reports a finished step with its token usage and cost, e.g.
+// "Step 2 done · 12.3k in / 456 out tokens · $0.0210".
+func (d *opencodeDecoder) formatStepFinish(part *opencodePart) string {
+	parts := []string{fmt.Sprintf("Step %d done", d.step)}
+	if part.Reason != "" && part.Reason != "stop" && part.Reason != "tool-calls" {
+		parts[0] += " (" + part.Reason + ")"
+	}
+	if t := part.Tokens; t != nil {
+		parts = append(parts, fmt.Sprintf("%s in / %s out tokens", formatCount(t.Input+t.Cache.Read), formatCount(t.Output+t.Reasoning)))
+	}
+	if part.Cost > 0 {
+		parts = append(parts, fmt.Sprintf("$%.4f", part.Cost))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// formatCount abbreviates token counts: 950, 12.3k, 1.2M.
+func formatCount(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1_000)
+	default:
+		return fmt.Sprint(n)
+	}
+}
@@ -0,0 +1,64 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// agentPipes connects an agent's stdout and stderr to OS pipes. Unlike
+// cmd.StdoutPipe, cmd.Wait does not close the read ends, so readers can drain
+// everything the agent wrote — including its final error or result event —
+// after the process exits.
+type agentPipes struct {
+	stdoutR, stdoutW *os.File
+	stderrR, stderrW *os.File
+}
+
+func newAgentPipes(cmd *exec.Cmd) (*agentPipes, error) {
+	stdoutR, stdoutW, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("stdout pipe: %w", err)
+	}
+	stderrR, stderrW, err := os.Pipe()
+	if err != nil {
+		stdoutR.Close()
+		stdoutW.Close()
+		return nil, fmt.Errorf("stderr pipe: %w", err)
+	}
+	cmd.Stdout = stdoutW
+	cmd.Stderr = stderrW
+	return &agentPipes{stdoutR: stdoutR, stdoutW: stdoutW, stderrR: stderrR, stderrW: stderrW}, nil
+}
+
+// closeWriters closes the parent's copies of the write ends; call it once the
+// agent has started (or failed to start) so readers see EOF when the agent exits.
+func (p *agentPipes) closeWriters() {
+	p.stdoutW.Close()
+	p.stderrW.Close()
+}
+
+// closeReaders closes the read ends, unblocking any reader still waiting for EOF.
+func (p *agentPipes) closeReaders() {
+	p.stdoutR.Close()
+	p.stderrR.Close()
+}
+
+// drainOutput waits for the output readers to reach EOF. If a lingering
+// grandchild still holds a pipe open after outputDrainTimeout, closeReaders is
+// called to unblock them.
+func drainOutput(readers *sync.WaitGroup, closeReaders func()) {
+	done := make(chan struct{})
+	go func() {
+		readers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(outputDrainTimeout):
+		closeReaders()
+		<-done
+	}
+}
@@ -0,0 +1,31 @@
+//go:build windows
+
+package agent
+
+import (
+	"os"
+	"os/exec"
+)
+
+// setProcessGroup is a no-op on Windows; there are no POSIX process groups.
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// signalProcessGroup kills the agent process. Windows cannot deliver SIGINT or
+// SIGTERM to another process, so every signal is treated as a kill.
+func signalProcessGroup(cmd *exec.Cmd, sig os.Signal) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return cmd.Process.Kill()
+}
+
+// processAlive reports whether a process with pid exists: on Windows, finding a
+// process opens it, which fails once it has exited.
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
@@ -0,0 +1,143 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Report collects a summary of every phase a command runs, for --report. A nil
+// *Report records nothing, so runners need not check whether reporting is on.
+type Report struct {
+	Command     string     `json:"command"`
+	ProjectRoot string     `json:"project_root"`
+	StatusFile  string     `json:"status_file"`
+	Start       time.Time  `json:"start"`
+	End         time.Time  `json:"end"`
+	DurationMS  int64      `json:"duration_ms"`
+	Result      string     `json:"result"` // ResultSuccess, ResultFailed, ...
+	Error       string     `json:"error,omitempty"`
+	Phases      []PhaseRun `json:"phases"`
+
+	// FailureAction describes what was done with the work of a failed story
+	// (run auto --on-failure).
+	FailureAction string `json:"failure_action,omitempty"`
+
+	mu sync.Mutex
+}
+
+// PhaseRun is one phase run in a Report. Retries of a phase are separate runs.
+type PhaseRun struct {
+	Story      string      `json:"story,omitempty"`
+	Phase      string      `json:"phase"`
+	Agent      string      `json:"agent"`
+	Model      string      `json:"model"`
+	Start      time.Time   `json:"start"`
+	End        time.Time   `json:"end"`
+	DurationMS int64       `json:"duration_ms"`
+	Result     string      `json:"result"`
+	Failure    FailureKind `json:"failure,omitempty"`
+	ExitCode   *int        `json:"exit_code,omitempty"`
+	Error      string      `json:"error,omitempty"`
+
+	// StatusChanged reports whether the sprint-status file changed during the run.
+	StatusChanged bool   `json:"status_changed"`
+	Transcript    string `json:"transcript,omitempty"`
+}
+
+// NewReport starts a report for command, run in projectRoot against statusFile.
+func NewReport(command, projectRoot, statusFile string) *Report {
+	return &Report{
+		Command:     command,
+		ProjectRoot: projectRoot,
+		StatusFile:  statusFile,
+		Start:       time.Now(),
+		Phases:      []PhaseRun{},
+	}
+}
+
+// pendingRun is a phase run in progress, with the sprint-status file the agent
+// edits and the contents it started with.
+type pendingRun struct {
+	run          PhaseRun
+	statusFile   string
+	statusBefore []byte
+}
+
+// begin records the start of a phase run. statusFile is the sprint-status file
+// the agent edits, if not the report's.
+func (r *Report) begin(story, phase, agentType, model, statusFile string) *pendingRun {
+	if r == nil {
+		return nil
+	}
+	if statusFile == "" {
+		statusFile = r.StatusFile
+	}
+	before, _ := os.ReadFile(statusFile)
+	return &pendingRun{
+		run:          PhaseRun{Story: story, Phase: phase, Agent: agentType, Model: model, Start: time.Now()},
+		statusFile:   statusFile,
+		statusBefore: before,
+	}
+}
+
+// finish records the outcome err of a phase run started with begin.
+func (r *Report) finish(p *pendingRun, err error) {
+	if r == nil || p == nil {
+		return
+	}
+	run := p.run
+	run.End = time.Now()
+	run.DurationMS = run.End.Sub(run.Start).Milliseconds()
+	run.Result, run.Failure, run.ExitCode = phaseOutcome(err)
+	if err != nil {
+		run.Error = err.Error()
+	}
+	after, _ := os.ReadFile(p.statusFile)
+	run.StatusChanged = !bytes.Equal(p.statusBefore, after)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Phases = append(r.Phases, run)
+}
+
+// SetFailureAction records what was done with the work of a failed story.
+func (r *Report) SetFailureAction(action string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.FailureAction = action
+}
+
+// WriteFile completes the report with the command's result err and writes it to
+// path as indented JSON.
+func (r *Report) WriteFile(path string, err error) error {
+	r.mu.Lock()
+	r.End = time.Now()
+	r.DurationMS = r.End.Sub(r.Start).Milliseconds()
+	r.Result, _, _ = phaseOutcome(err)
+	if err != nil {
+		r.Error = err.Error()
+	}
+	data, merr := json.MarshalIndent(r, "", "  ")
+	r.mu.Unlock()
+	if merr != nil {
+		return fmt.Errorf("encoding report: %w", merr)
+	}
+
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("creating report directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing report: %w", err)
+	}
+	return nil
+}
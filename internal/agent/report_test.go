@@ -0,0 +1,87 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	statusFile := filepath.Join(dir, "sprint-status.yaml")
+	if err := os.WriteFile(statusFile, []byte("a: backlog\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rep := NewReport("run auto", dir, statusFile)
+	p := rep.begin("1-1-a", "dev-story", "claude-code", "opus", "")
+	if err := os.WriteFile(statusFile, []byte("a: in-review\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rep.finish(p, nil)
+	p = rep.begin("1-1-a", "code-review", "gemini-cli", "gemini-3-pro", "")
+	rep.finish(p, &PhaseError{Phase: "code-review", Kind: FailureRateLimit, ExitCode: 1, Err: errors.New("exit status 1")})
+	p = rep.begin("1-1-a", "code-review", "gemini-cli", "gemini-3-pro", "")
+	rep.finish(p, fmt.Errorf("phase code-review: %w", ErrInterrupted))
+	rep.SetFailureAction("rolled back 1-1-a")
+
+	path := filepath.Join(dir, "reports", "run.json")
+	if err := rep.WriteFile(path, ErrInterrupted); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Report
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("decoding report: %v", err)
+	}
+
+	if got.Command != "run auto" || got.Result != ResultInterrupted || got.StatusFile != statusFile {
+		t.Errorf("report = %s/%s/%s, want run auto/interrupted/%s", got.Command, got.Result, got.StatusFile, statusFile)
+	}
+	if got.FailureAction != "rolled back 1-1-a" {
+		t.Errorf("failure action = %q, want %q", got.FailureAction, "rolled back 1-1-a")
+	}
+	if len(got.Phases) != 3 {
+		t.Fatalf("got %d phases, want 3", len(got.Phases))
+	}
+	tests := []struct {
+		phase    string
+		result   string
+		failure  FailureKind
+		exitCode int // -2: none
+		changed  bool
+	}{
+		{"dev-story", ResultSuccess, "", 0, true},
+		{"code-review", ResultFailed, FailureRateLimit, 1, false},
+		{"code-review", ResultInterrupted, FailureInterrupted, -2, false},
+	}
+	for i, tt := range tests {
+		run := got.Phases[i]
+		if run.Phase != tt.phase || run.Result != tt.result || run.Failure != tt.failure || run.StatusChanged != tt.changed {
+			t.Errorf("phase %d = %s/%s/%s changed=%v, want %s/%s/%s changed=%v", i, run.Phase, run.Result, run.Failure, run.StatusChanged, tt.phase, tt.result, tt.failure, tt.changed)
+		}
+		switch {
+		case tt.exitCode == -2 && run.ExitCode != nil:
+			t.Errorf("phase %d exit code = %d, want none", i, *run.ExitCode)
+		case tt.exitCode != -2 && (run.ExitCode == nil || *run.ExitCode != tt.exitCode):
+			t.Errorf("phase %d exit code = %v, want %d", i, run.ExitCode, tt.exitCode)
+		}
+		if run.Story != "1-1-a" || run.End.Before(run.Start) {
+			t.Errorf("phase %d = story %q, %s–%s", i, run.Story, run.Start, run.End)
+		}
+	}
+}
+
+func TestNilReport(t *testing.T) {
+	t.Parallel()
+	var rep *Report
+	rep.finish(rep.begin("1-1-a", "dev-story", "claude-code", "opus", ""), nil)
+	rep.SetFailureAction("kept")
+}
@@ -0,0 +1,75 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pterm/pterm"
+)
+
+// DefaultMaxBackoff caps the delay between retries when RetryPolicy.MaxBackoff is zero.
+const DefaultMaxBackoff = 10 * time.Minute
+
+// RetryPolicy controls how transient phase failures (rate limits, overload,
+// network errors, crashes) are retried. The zero value runs each phase once.
+type RetryPolicy struct {
+	// MaxRetries is how many times a transiently failing phase is re-run.
+	MaxRetries int
+	// Backoff is the delay before the first retry; it doubles on each further retry.
+	Backoff time.Duration
+	// MaxBackoff caps the delay. Zero means DefaultMaxBackoff.
+	MaxBackoff time.Duration
+	// FallbackModel, if set and different from the phase model, gets one final
+	// attempt after the retries are used up.
+	FallbackModel string
+}
+
+// Do calls run with model, retrying transient failures with exponential backoff.
+// Non-transient failures (auth, timeouts, unknown exits) are returned immediately.
+// Cancelling ctx while waiting returns an error wrapping ErrInterrupted.
+func (p RetryPolicy) Do(ctx context.Context, phase, model string, run func(model string) error) error {
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMaxBackoff
+	}
+	delay := p.Backoff
+	attempts := p.MaxRetries + 1
+	fallback := p.FallbackModel != "" && p.FallbackModel != model
+	if fallback {
+		attempts++
+	}
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = run(model); err == nil {
+			return nil
+		}
+		kind := Classify(err)
+		if !kind.Transient() || attempt == attempts {
+			return err
+		}
+
+		if fallback && attempt == attempts-1 {
+			pterm.Warning.Printf("Phase %s failed (%s) — retries exhausted, falling back to model %s\n", phase, kind, p.FallbackModel)
+			model = p.FallbackModel
+		} else {
+			pterm.Warning.Printf("Phase %s failed (%s) — retry %d/%d\n", phase, kind, attempt, p.MaxRetries)
+		}
+		if delay > 0 {
+			pterm.Info.Printf("Waiting %s before retrying...\n", delay)
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return fmt.Errorf("waiting to retry phase %s: %w", phase, ErrInterrupted)
+			case <-timer.C:
+			}
+			delay *= 2
+			if delay > maxBackoff {
+				delay = maxBackoff
+			}
+		}
+	}
+	return err
+}
@@ -3,7 +3,7 @@ package agent
 import (
 	"bufio"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -22,30 +22,131 @@ const (
 	// lastLinesMax is the cordoned agent output line count (3 or 5). Bump to 5 if more context needed.
 	lastLinesMax  = 3
 	frameInterval = 80 * time.Millisecond
+	// diagLinesMax is how many error/stderr lines are kept for failure classification.
+	diagLinesMax = 20
+	// outputDrainTimeout bounds how long to wait for output after the agent exits,
+	// in case a lingering grandchild process keeps a pipe open.
+	outputDrainTimeout = 2 * time.Second
 )
 
-// Runner orchestrates cursor-agent or claude-code invocations
+// Runner orchestrates agent CLI invocations through the backend named by AgentType
 type Runner struct {
 	AgentPath    string
 	AgentType    string
 	ProjectRoot  string
 	NoLiveStatus bool // disable last-lines display in spinner (e.g. CI, --no-live-status)
+	UsePTY       bool // drive the agent through a PTY and show its text output, for backends with a PTY fallback (gemini-cli)
+
+	// ShutdownGrace is how long the agent gets to exit after an interrupt is
+	// forwarded to it before its process group is killed. Zero means DefaultShutdownGrace.
+	ShutdownGrace time.Duration
+
+	// PhaseTimeouts caps the wall-clock duration of a phase, keyed by phase name.
+	// The "default" entry applies to phases without their own entry; zero means no limit.
+	PhaseTimeouts map[string]time.Duration
+
+	// IdleTimeout kills the agent when it has produced no output for this long.
+	// Zero disables the watchdog.
+	IdleTimeout time.Duration
+
+	// TranscriptDir is where each phase run's full transcript is written.
+	// Empty disables transcripts.
+	TranscriptDir string
+
+	// StoryKey labels transcripts with the story (or, for retrospectives, the epic)
+	// being worked on. Optional.
+	StoryKey string
+
+	// Report, if set, records a summary of every phase run (see --report).
+	Report *Report
+
+	// StatusFile is the sprint-status file the agent edits when it is not the
+	// Report's, e.g. the copy in a story worktree. Optional.
+	StatusFile string
+
+	// Progress, if set, receives the agent's latest output lines while a phase
+	// runs, and the runner prints no header or live display of its own. It lets
+	// the caller show several runners at once (run auto --parallel).
+	Progress func(lines []string)
+
+	// Output, if set, receives the agent's output lines instead of stdout and
+	// stderr when NoLiveStatus is set, e.g. to label them with the story when
+	// several runners share the terminal. No spinner is shown with Progress set.
+	Output io.Writer
+}
+
+// transcriptWarning makes a failure to create transcripts print once per session
+// rather than once per phase run.
+var transcriptWarning sync.Once
+
+// phaseView shows a running phase: a ui.PhaseDisplay, or Runner.Progress.
+type phaseView interface {
+	Tick(lines []string)
+	Success()
+	Fail()
+}
+
+// progressView passes a phase's output lines to a Runner.Progress callback.
+type progressView func(lines []string)
+
+func (f progressView) Tick(lines []string) { f(lines) }
+func (progressView) Success()              {}
+func (progressView) Fail()                 {}
+
+// spinnerView shows a running phase as a plain spinner, for CI mode.
+type spinnerView struct {
+	spinner *pterm.SpinnerPrinter
+	phase   string
+}
+
+func newSpinnerView(phase string) spinnerView {
+	spinner, _ := ui.NewPhaseSpinner().Start(fmt.Sprintf("Executing %s...", phase))
+	return spinnerView{spinner: spinner, phase: phase}
+}
+
+func (spinnerView) Tick([]string) {}
+func (v spinnerView) Success()    { v.spinner.Success(fmt.Sprintf("Phase %s completed", v.phase)) }
+func (v spinnerView) Fail()       { v.spinner.Fail(fmt.Sprintf("Phase %s failed", v.phase)) }
+
+// phaseTimeout returns the wall-clock limit for phase, or 0 if there is none.
+func (r *Runner) phaseTimeout(phase string) time.Duration {
+	if d, ok := r.PhaseTimeouts[phase]; ok {
+		return d
+	}
+	return r.PhaseTimeouts["default"]
 }
 
 // lastLinesBuffer is a thread-safe rolling buffer of the last N lines.
+// It also records when output last arrived, which drives the idle watchdog.
 type lastLinesBuffer struct {
-	mu    sync.Mutex
-	lines []string
-	max   int
+	mu       sync.Mutex
+	lines    []string
+	max      int
+	lastSeen time.Time
+}
+
+// touch records that the agent produced output, even if nothing displayable.
+func (b *lastLinesBuffer) touch() {
+	b.mu.Lock()
+	b.lastSeen = time.Now()
+	b.mu.Unlock()
+}
+
+// idleFor returns how long it has been since the last push or touch.
+func (b *lastLinesBuffer) idleFor() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return time.Since(b.lastSeen)
 }
 
 func (b *lastLinesBuffer) push(line string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lastSeen = time.Now()
 	line = strings.TrimSpace(line)
 	if line == "" {
 		return
 	}
-	b.mu.Lock()
-	defer b.mu.Unlock()
 	b.lines = append(b.lines, line)
 	if len(b.lines) > b.max {
 		b.lines = b.lines[len(b.lines)-b.max:]
@@ -60,15 +161,35 @@ func (b *lastLinesBuffer) get() []string {
 	return out
 }
 
-// readPipe reads from r, tees each line to w (if non-nil), and pushes complete lines to buf.
-func readPipe(r io.Reader, w io.Writer, buf *lastLinesBuffer) {
+// phaseOutput fans out everything the agent prints during a phase: status lines
+// go to the live preview, lines that may explain a failure to the diagnostic tail,
+// and every raw line to the transcript (if enabled).
+type phaseOutput struct {
+	preview    *lastLinesBuffer
+	diag       *lastLinesBuffer
+	transcript *Transcript
+	decoder    LineDecoder // the structured stdout decoder, if any
+}
+
+// record notes one raw line from stream. It does not touch the preview text.
+func (o *phaseOutput) record(stream, line string) {
+	o.preview.touch()
+	noteDiagnostic(o.diag, o.decoder, stream, line)
+	o.transcript.output(stream, line)
+}
+
+// readPipe reads from r, tees each line to w (if non-nil), records it as stream,
+// and pushes complete lines to the preview.
+func readPipe(r io.Reader, w io.Writer, out *phaseOutput, stream string) {
 	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 256*1024), 1024*1024)
 	for scanner.Scan() {
 		line := scanner.Text()
 		if w != nil {
 			fmt.Fprintln(w, line)
 		}
-		buf.push(line)
+		out.record(stream, line)
+		out.preview.push(line)
 	}
 }
 
@@ -95,123 +216,50 @@ func scanTermLines(data []byte, atEOF bool) (advance int, token []byte, err erro
 	return 0, nil, nil
 }
 
-// readPTY reads from a PTY master, splitting on \n, \r\n, or bare \r,
-// and pushes non-empty lines into buf.
-func readPTY(r io.Reader, buf *lastLinesBuffer) {
+// readPTY reads from a PTY master, splitting on \n, \r\n, or bare \r. Each line is
+// stripped of escape sequences and spinner frames; empty lines and repeats of the
+// previous line (spinner redraws) are dropped, and do not count as output for the
+// idle watchdog.
+func readPTY(r io.Reader, out *phaseOutput) {
 	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 256*1024), 1024*1024)
 	scanner.Split(scanTermLines)
+	var prev string
 	for scanner.Scan() {
-		buf.push(scanner.Text())
+		line := cleanTerminalLine(scanner.Text())
+		if line == "" || line == prev {
+			continue
+		}
+		prev = line
+		out.record("pty", line)
+		out.preview.push(line)
 	}
 }
 
-// --- claude-code stream-json parsing ---
-
-type claudeEvent struct {
-	Type    string     `json:"type"`
-	Message *claudeMsg `json:"message,omitempty"`
-}
-
-type claudeMsg struct {
-	Content []claudeBlock `json:"content"`
-}
-
-type claudeBlock struct {
-	Type  string          `json:"type"` // "text" or "tool_use"
-	Text  string          `json:"text,omitempty"`
-	Name  string          `json:"name,omitempty"`
-	Input json.RawMessage `json:"input,omitempty"`
-}
-
-// readStreamJSON reads JSONL from an agent's --output-format stream-json stdout,
-// extracts human-readable status lines, and pushes them to buf.
-func readStreamJSON(r io.Reader, buf *lastLinesBuffer) {
+// readStreamJSON reads JSONL from an agent's structured stdout, records each event,
+// decodes human-readable status lines with dec, and pushes them to the preview.
+func readStreamJSON(r io.Reader, out *phaseOutput, dec LineDecoder) {
 	scanner := bufio.NewScanner(r)
 	scanner.Buffer(make([]byte, 0, 256*1024), 1024*1024)
 	for scanner.Scan() {
 		raw := scanner.Bytes()
 		if len(raw) == 0 {
+			out.preview.touch()
 			continue
 		}
-		var ev claudeEvent
-		if err := json.Unmarshal(raw, &ev); err != nil {
-			continue
-		}
-		for _, line := range extractClaudeStatus(&ev) {
-			buf.push(line)
+		out.record("stdout", string(raw))
+		for _, line := range dec.Decode(raw) {
+			out.preview.push(line)
 		}
 	}
 }
 
-func extractClaudeStatus(ev *claudeEvent) []string {
-	if ev.Type != "assistant" || ev.Message == nil {
-		return nil
-	}
-	var lines []string
-	for _, block := range ev.Message.Content {
-		switch block.Type {
-		case "tool_use":
-			lines = append(lines, formatToolUse(block))
-		case "text":
-			if last := lastNonEmptyLine(block.Text); last != "" {
-				lines = append(lines, last)
-			}
-		}
-	}
-	return lines
-}
-
-func formatToolUse(block claudeBlock) string {
-	var pathInput struct {
-		Path string `json:"file_path"`
-	}
-	var cmdInput struct {
-		Command string `json:"command"`
-	}
-	switch block.Name {
-	case "Read", "read_file":
-		if json.Unmarshal(block.Input, &pathInput) == nil && pathInput.Path != "" {
-			return "Reading " + pathInput.Path
-		}
-	case "Edit", "edit_file":
-		if json.Unmarshal(block.Input, &pathInput) == nil && pathInput.Path != "" {
-			return "Editing " + pathInput.Path
-		}
-	case "Write", "write_file":
-		if json.Unmarshal(block.Input, &pathInput) == nil && pathInput.Path != "" {
-			return "Writing " + pathInput.Path
-		}
-	case "Bash", "execute_command":
-		if json.Unmarshal(block.Input, &cmdInput) == nil && cmdInput.Command != "" {
-			c := cmdInput.Command
-			if len(c) > 50 {
-				c = c[:47] + "..."
-			}
-			return "Running: " + c
-		}
-	case "Glob", "glob":
-		return "Tool: Glob"
-	case "Grep", "grep":
-		return "Tool: Grep"
-	}
-	return "Tool: " + block.Name
-}
-
-func lastNonEmptyLine(s string) string {
-	lines := strings.Split(strings.TrimSpace(s), "\n")
-	for i := len(lines) - 1; i >= 0; i-- {
-		if l := strings.TrimSpace(lines[i]); l != "" {
-			return l
-		}
-	}
-	return ""
-}
-
 // --- main Run logic ---
 
 // Run executes a BMAD workflow phase (create-story, dev-story, code-review) by reading
 // the phase's command file and running the agent with a yolo preamble.
-func (r *Runner) Run(phase string, model string) error {
+// Cancelling ctx interrupts the agent and returns an error wrapping ErrInterrupted.
+func (r *Runner) Run(ctx context.Context, phase string, model string) error {
 	// 1. Read command file — check agent-specific directory first, then fall back to .cursor
 	commandFile := r.resolveCommandFile(phase)
 	data, err := os.ReadFile(commandFile)
@@ -222,7 +270,7 @@ func (r *Runner) Run(phase string, model string) error {
 	// 2. Build prompt with yolo preamble
 	prompt := buildYoloPrompt(string(data))
 
-	return r.runPrompt(prompt, phase, model)
+	return r.runPrompt(ctx, prompt, phase, model)
 }
 
 // resolveCommandFile returns the path of the command file for a given phase.
@@ -230,16 +278,7 @@ func (r *Runner) Run(phase string, model string) error {
 // .cursor/commands for cursor-agent / gemini-cli), then falls back to .cursor/commands.
 func (r *Runner) resolveCommandFile(phase string) string {
 	filename := fmt.Sprintf("bmad-bmm-%s.md", phase)
-
-	var preferred string
-	switch r.AgentType {
-	case "opencode":
-		preferred = filepath.Join(r.ProjectRoot, ".opencode", "commands", filename)
-case "claude-code":
-		preferred = filepath.Join(r.ProjectRoot, ".claude", "commands", filename)
-	default:
-		preferred = filepath.Join(r.ProjectRoot, ".cursor", "commands", filename)
-	}
+	preferred := filepath.Join(r.ProjectRoot, BackendFor(r.AgentType).CommandDir(), filename)
 
 	if _, err := os.Stat(preferred); err == nil {
 		return preferred
@@ -252,15 +291,15 @@ case "claude-code":
 // RunWithPrompt executes an agent phase using a pre-built prompt string instead of
 // reading from a command file. Use this for dynamically generated phases where the
 // prompt is constructed entirely in Go.
-func (r *Runner) RunWithPrompt(prompt, phase, model string) error {
-	return r.runPrompt(prompt, phase, model)
+func (r *Runner) RunWithPrompt(ctx context.Context, prompt, phase, model string) error {
+	return r.runPrompt(ctx, prompt, phase, model)
 }
 
 // RunPhaseWithContext reads the BMAD command file for the given phase, prepends a
 // targeted context block, and runs the agent. The context block narrows the scope of
 // the BMAD workflow (e.g. "add one incremental epic") while still driving the real
 // BMAD workflow rather than generating content from scratch.
-func (r *Runner) RunPhaseWithContext(context, phase, model string) error {
+func (r *Runner) RunPhaseWithContext(ctx context.Context, contextBlock, phase, model string) error {
 	commandFile := r.resolveCommandFile(phase)
 	data, err := os.ReadFile(commandFile)
 	if err != nil {
@@ -268,126 +307,184 @@ func (r *Runner) RunPhaseWithContext(context, phase, model string) error {
 	}
 
 	// context + yolo preamble wrapping the actual BMAD command content
-	prompt := buildYoloPrompt(context + string(data))
+	prompt := buildYoloPrompt(contextBlock + string(data))
 
-	return r.runPrompt(prompt, phase, model)
+	return r.runPrompt(ctx, prompt, phase, model)
 }
 
 // runPrompt is the shared implementation that executes an agent with a given prompt.
-func (r *Runner) runPrompt(prompt, phase, model string) error {
-	var cmd *exec.Cmd
-	switch r.AgentType {
-	case "claude-code":
-		cmd = exec.Command(r.AgentPath,
-			"-p",
-			"--output-format", "stream-json",
-			"--verbose",
-			"--model", model,
-			"--dangerously-skip-permissions",
-			prompt,
-		)
-	case "gemini-cli":
-		cmd = exec.Command(r.AgentPath,
-			"--approval-mode", "yolo",
-			"--model", model,
-			"-p",
-			prompt,
-		)
-	case "opencode":
-		cmd = exec.Command(r.AgentPath,
-			"run",
-			"--model", model,
-			"--format", "json",
-			prompt,
-		)
-	default:
-		cmd = exec.Command(r.AgentPath,
-			"-p",
-			"--output-format", "stream-json",
-			"-f",
-			"--approve-mcps",
-			"--model", model,
-			"--workspace", r.ProjectRoot,
-			prompt,
-		)
+// The agent runs in its own process group; when ctx is cancelled, the phase timeout
+// expires or the idle watchdog fires, the interrupt is forwarded to the whole group
+// and escalated to SIGKILL after ShutdownGrace.
+func (r *Runner) runPrompt(ctx context.Context, prompt, phase, model string) (retErr error) {
+	if ctx.Err() != nil {
+		return fmt.Errorf("phase %s not started: %w", phase, ErrInterrupted)
 	}
 
+	runCtx, cancelRun := context.WithCancelCause(ctx)
+	defer cancelRun(nil)
+	timeout := r.phaseTimeout(phase)
+	if timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		runCtx, cancelTimeout = context.WithTimeoutCause(runCtx, timeout, ErrPhaseTimeout)
+		defer cancelTimeout()
+	}
+
+	backend := BackendFor(r.AgentType)
+	caps := backend.Capabilities()
+	usePTY := r.UsePTY && caps.PTYFallback
+	inv := Invocation{
+		Prompt:      prompt,
+		Model:       model,
+		ProjectRoot: r.ProjectRoot,
+		UsePTY:      usePTY,
+	}
+	if caps.PromptFile {
+		promptFile, err := writePromptFile(prompt)
+		if err != nil {
+			return fmt.Errorf("writing prompt file for phase %s: %w", phase, err)
+		}
+		defer os.Remove(promptFile)
+		inv.PromptFile = promptFile
+	}
+	cmd := exec.Command(r.AgentPath, backend.Args(inv)...)
 	cmd.Dir = r.ProjectRoot
 
-	pterm.DefaultSection.Printf("BMAD Workflow: %s", strings.ReplaceAll(phase, "-", " "))
-	pterm.Info.Printf("Project Root: %s\n", r.ProjectRoot)
-	pterm.Info.Printf("Agent:        %s\n", r.AgentType)
-	pterm.Info.Printf("Model:        %s\n", model)
+	if r.Progress == nil {
+		pterm.DefaultSection.Printf("BMAD Workflow: %s", strings.ReplaceAll(phase, "-", " "))
+		pterm.Info.Printf("Project Root: %s\n", r.ProjectRoot)
+		pterm.Info.Printf("Agent:        %s\n", r.AgentType)
+		pterm.Info.Printf("Model:        %s\n", model)
+	}
 
-	buf := &lastLinesBuffer{max: lastLinesMax}
+	reported := r.Report.begin(r.StoryKey, phase, r.AgentType, model, r.StatusFile)
+	defer func() { r.Report.finish(reported, retErr) }()
+
+	buf := &lastLinesBuffer{max: lastLinesMax, lastSeen: time.Now()}
+	out := &phaseOutput{preview: buf, diag: &lastLinesBuffer{max: diagLinesMax}}
+	if r.TranscriptDir != "" {
+		transcript, err := createTranscript(r.TranscriptDir, TranscriptRecord{
+			Time:        time.Now(),
+			Phase:       phase,
+			Story:       r.StoryKey,
+			Agent:       r.AgentType,
+			Model:       model,
+			ProjectRoot: r.ProjectRoot,
+			Prompt:      prompt,
+			PID:         os.Getpid(),
+		})
+		if err != nil {
+			transcriptWarning.Do(func() { pterm.Warning.Printf("Transcript disabled: %v\n", err) })
+		} else {
+			if r.Progress == nil {
+				pterm.Info.Printf("Transcript:   %s\n", transcript.Path)
+			}
+			out.transcript = transcript
+			if reported != nil {
+				reported.run.Transcript = transcript.Path
+			}
+			defer func() { transcript.finish(retErr) }()
+		}
+	}
+	if r.IdleTimeout > 0 {
+		go watchIdle(runCtx, buf, r.IdleTimeout, cancelRun)
+	}
 
 	var runErr error
+	var readers sync.WaitGroup
 
 	if r.NoLiveStatus {
-		// CI/script mode: pipe output directly to terminal, plain spinner for progress.
-		stdoutPipe, err := cmd.StdoutPipe()
-		if err != nil {
-			return fmt.Errorf("stdout pipe: %w", err)
-		}
-		stderrPipe, err := cmd.StderrPipe()
+		// CI/script mode: pipe output directly to terminal (or Output), plain
+		// spinner for progress unless the caller shows it.
+		pipes, err := newAgentPipes(cmd)
 		if err != nil {
-			return fmt.Errorf("stderr pipe: %w", err)
+			return err
 		}
-		go readPipe(stdoutPipe, os.Stdout, buf)
-		go readPipe(stderrPipe, os.Stderr, buf)
+		defer pipes.closeReaders()
 
-		spinner, _ := ui.NewPhaseSpinner().Start(fmt.Sprintf("Executing %s...", phase))
+		var view phaseView = progressView(r.Progress)
+		if r.Progress == nil {
+			view = newSpinnerView(phase)
+		}
+		var stdout, stderr io.Writer = os.Stdout, os.Stderr
+		if r.Output != nil {
+			stdout, stderr = r.Output, r.Output
+		}
+		setProcessGroup(cmd)
 		if err := cmd.Start(); err != nil {
-			spinner.Fail(fmt.Sprintf("Phase %s failed", phase))
+			pipes.closeWriters()
+			view.Fail()
 			return fmt.Errorf("agent start failed for phase %s: %w", phase, err)
 		}
+		pipes.closeWriters()
+		readers.Add(2)
+		go func() { defer readers.Done(); readPipe(pipes.stdoutR, stdout, out, "stdout") }()
+		go func() { defer readers.Done(); readPipe(pipes.stderrR, stderr, out, "stderr") }()
+
+		stop := superviseProcess(runCtx, cmd, r.ShutdownGrace)
 		runErr = cmd.Wait()
+		stop()
+		drainOutput(&readers, pipes.closeReaders)
 		if runErr != nil {
-			spinner.Fail(fmt.Sprintf("Phase %s failed", phase))
+			view.Fail()
 		} else {
-			spinner.Success(fmt.Sprintf("Phase %s completed", phase))
+			view.Success()
 		}
 	} else {
 		// Live mode: consume all agent output (no forwarding to terminal).
-		// Only the PhaseDisplay shows a rolling 3-line preview in place.
-		display := ui.NewPhaseDisplay(phase, lastLinesMax)
+		// Only the PhaseDisplay (or Progress) shows a rolling 3-line preview in place.
+		var display phaseView = progressView(r.Progress)
+		if r.Progress == nil {
+			display = ui.NewPhaseDisplay(phase, lastLinesMax)
+		}
+		var closeReaders func()
 
-		if r.AgentType == "gemini-cli" {
-			// gemini-cli: use PTY so the agent streams output in real time.
+		if usePTY {
+			// PTY fallback: the agent only streams its text output in real time on a terminal.
+			// pty.Start makes the agent a session leader, which also gives it its own process group.
 			ptmx, err := pty.Start(cmd)
 			if err != nil {
 				display.Fail()
 				return fmt.Errorf("agent start failed for phase %s: %w", phase, err)
 			}
 			defer ptmx.Close()
-			go readPTY(ptmx, buf)
+			closeReaders = func() { ptmx.Close() }
+			readers.Add(1)
+			go func() { defer readers.Done(); readPTY(ptmx, out) }()
 		} else {
-			// claude-code / cursor-agent: stream JSONL events via --output-format stream-json.
-			stdoutPipe, err := cmd.StdoutPipe()
-			if err != nil {
-				display.Fail()
-				return fmt.Errorf("stdout pipe: %w", err)
-			}
-			stderrPipe, err := cmd.StderrPipe()
+			// Pipes: structured output is decoded by the backend; plain text is shown as-is.
+			pipes, err := newAgentPipes(cmd)
 			if err != nil {
 				display.Fail()
-				return fmt.Errorf("stderr pipe: %w", err)
+				return err
 			}
+			defer pipes.closeReaders()
+			setProcessGroup(cmd)
 			if err := cmd.Start(); err != nil {
+				pipes.closeWriters()
 				display.Fail()
 				return fmt.Errorf("agent start failed for phase %s: %w", phase, err)
 			}
-			go readStreamJSON(stdoutPipe, buf)
-			go readPipe(stderrPipe, nil, buf)
+			pipes.closeWriters()
+			closeReaders = pipes.closeReaders
+			readers.Add(2)
+			if dec := backend.NewDecoder(); caps.StructuredOutput && dec != nil {
+				out.decoder = dec
+				go func() { defer readers.Done(); readStreamJSON(pipes.stdoutR, out, dec) }()
+			} else {
+				go func() { defer readers.Done(); readPipe(pipes.stdoutR, nil, out, "stdout") }()
+			}
+			go func() { defer readers.Done(); readPipe(pipes.stderrR, nil, out, "stderr") }()
 		}
 
-		ctx, cancel := context.WithCancel(context.Background())
+		stop := superviseProcess(runCtx, cmd, r.ShutdownGrace)
+		tickDone := make(chan struct{})
 		go func() {
 			ticker := time.NewTicker(frameInterval)
 			defer ticker.Stop()
 			for {
 				select {
-				case <-ctx.Done():
+				case <-tickDone:
 					return
 				case <-ticker.C:
 					display.Tick(buf.get())
@@ -396,7 +493,9 @@ func (r *Runner) runPrompt(prompt, phase, model string) error {
 		}()
 
 		runErr = cmd.Wait()
-		cancel()
+		stop()
+		drainOutput(&readers, closeReaders)
+		close(tickDone)
 
 		if runErr != nil {
 			display.Fail()
@@ -405,12 +504,36 @@ func (r *Runner) runPrompt(prompt, phase, model string) error {
 		}
 	}
 
+	if runCtx.Err() != nil {
+		switch cause := context.Cause(runCtx); {
+		case errors.Is(cause, ErrPhaseTimeout):
+			return fmt.Errorf("phase %s exceeded its %s limit: %w", phase, timeout, ErrPhaseTimeout)
+		case errors.Is(cause, ErrIdleTimeout):
+			return fmt.Errorf("phase %s produced no output for %s: %w", phase, r.IdleTimeout, ErrIdleTimeout)
+		default:
+			return fmt.Errorf("phase %s: %w", phase, ErrInterrupted)
+		}
+	}
 	if runErr != nil {
-		return fmt.Errorf("agent execution failed for phase %s: %w", phase, runErr)
+		return newPhaseError(phase, model, runErr, out.diag.get())
 	}
 	return nil
 }
 
+// writePromptFile writes prompt to a new temporary file and returns its path.
+func writePromptFile(prompt string) (string, error) {
+	f, err := os.CreateTemp("", "bmad-runner-prompt-*.md")
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.WriteString(prompt); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), f.Close()
+}
+
 func buildYoloPrompt(commandContent string) string {
 	var sb strings.Builder
 	sb.WriteString("Execute the following BMAD workflow. CRITICAL: Run in #yolo mode from the start.\n")
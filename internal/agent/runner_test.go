@@ -0,0 +1,76 @@
+package agent
+
+import (
+	"bufio"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScanTermLines(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"newlines", "a\nb\n", []string{"a", "b"}},
+		{"crlf", "a\r\nb\r\n", []string{"a", "b"}},
+		{"bare carriage return", "50%\r100%\rdone\n", []string{"50%", "100%", "done"}},
+		{"no trailing newline", "a\nb", []string{"a", "b"}},
+		{"empty lines kept", "a\n\nb\n", []string{"a", "", "b"}},
+		{"empty input", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			scanner := bufio.NewScanner(strings.NewReader(tt.input))
+			scanner.Split(scanTermLines)
+			var got []string
+			for scanner.Scan() {
+				got = append(got, scanner.Text())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("scanTermLines(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCleanTerminalLine(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "Reading file", "Reading file"},
+		{"colours", "\x1b[1;32mDone\x1b[0m", "Done"},
+		{"private mode and cursor", "\x1b[?25l\x1b[2K\x1b[1GWorking\x1b[?25h", "Working"},
+		{"osc title bel", "\x1b]0;gemini - app\x07Hello", "Hello"},
+		{"osc hyperlink st", "\x1b]8;;https://x.dev\x1b\\link\x1b]8;;\x1b\\", "link"},
+		{"spinner frame", "⠋ Thinking... (esc to cancel, 3s)", "Thinking... (esc to cancel, 3s)"},
+		{"coloured spinner", "\x1b[36m⠙\x1b[0m  Generating", "Generating"},
+		{"control chars", "a\x07b\x00c\td", "abc d"},
+		{"bullets and paths kept", "- /work/app/main.go", "- /work/app/main.go"},
+		{"only escapes", "\x1b[2K\x1b[1A", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := cleanTerminalLine(tt.in); got != tt.want {
+				t.Errorf("cleanTerminalLine(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadPTYDropsRedraws(t *testing.T) {
+	t.Parallel()
+	out := &phaseOutput{preview: &lastLinesBuffer{max: 10}, diag: &lastLinesBuffer{max: 10}}
+	readPTY(strings.NewReader("⠋ Working\r⠙ Working\r⠹ Working\r\x1b[2K\rDone\n\n"), out)
+	want := []string{"Working", "Done"}
+	if got := out.preview.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("preview = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,70 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// DefaultShutdownGrace is how long an interrupted agent has to exit after the
+// interrupt signal before its process group is killed.
+const DefaultShutdownGrace = 10 * time.Second
+
+// ErrInterrupted is returned (wrapped) when a phase is stopped because its context
+// was cancelled, e.g. by Ctrl-C or SIGTERM.
+var ErrInterrupted = errors.New("agent interrupted")
+
+// SignalCause records which OS signal cancelled a context. Pass it to
+// context.WithCancelCause's cancel func so the Runner forwards the same signal
+// to the agent's process group.
+type SignalCause struct {
+	Signal os.Signal
+}
+
+func (c SignalCause) Error() string {
+	return "received " + c.Signal.String()
+}
+
+// interruptSignal returns the signal to forward to the agent when ctx is done:
+// the signal recorded in a SignalCause, or os.Interrupt otherwise.
+func interruptSignal(ctx context.Context) os.Signal {
+	var sc SignalCause
+	if errors.As(context.Cause(ctx), &sc) && sc.Signal != nil {
+		return sc.Signal
+	}
+	return os.Interrupt
+}
+
+// superviseProcess forwards cancellation of ctx to cmd's process group. The
+// interrupt signal is sent first; SIGKILL follows once the agent exits or grace
+// elapses, whichever comes first, so no grandchildren outlive an interrupted agent.
+// The returned stop func must be called once cmd.Wait returns.
+func superviseProcess(ctx context.Context, cmd *exec.Cmd, grace time.Duration) (stop func()) {
+	if grace <= 0 {
+		grace = DefaultShutdownGrace
+	}
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+		}
+		_ = signalProcessGroup(cmd, interruptSignal(ctx))
+		timer := time.NewTimer(grace)
+		defer timer.Stop()
+		select {
+		case <-done:
+		case <-timer.C:
+		}
+		_ = signalProcessGroup(cmd, os.Kill)
+	}()
+	return func() {
+		close(done)
+		<-finished
+	}
+}
@@ -0,0 +1,39 @@
+package agent
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// terminalEscape matches the escape sequences interactive CLIs write to a PTY:
+// CSI (colours, cursor movement, private modes like \x1b[?25l), OSC (window
+// titles, hyperlinks; BEL- or ST-terminated) and two-byte ESC sequences.
+var terminalEscape = regexp.MustCompile(`\x1b\[[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)?|\x1b[@-Z\\-_]`)
+
+// isSpinnerGlyph reports whether r is a spinner frame: braille patterns (⠋⠙⠹…)
+// and the rotating circles some CLIs animate with. ASCII spinners (|/-\) are
+// left alone since they are indistinguishable from list bullets and paths.
+func isSpinnerGlyph(r rune) bool {
+	if r >= 0x2800 && r <= 0x28FF {
+		return true
+	}
+	return strings.ContainsRune("◐◓◑◒◴◷◶◵", r)
+}
+
+// cleanTerminalLine strips escape sequences, control characters and leading
+// spinner frames from a PTY-captured line so only its text reaches the display.
+func cleanTerminalLine(line string) string {
+	line = terminalEscape.ReplaceAllString(line, "")
+	line = strings.Map(func(r rune) rune {
+		if r == '\t' {
+			return ' '
+		}
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, line)
+	line = strings.TrimLeftFunc(line, func(r rune) bool { return isSpinnerGlyph(r) || unicode.IsSpace(r) })
+	return strings.TrimSpace(line)
+}
@@ -0,0 +1,40 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrPhaseTimeout is returned (wrapped) when a phase exceeds its wall-clock limit.
+	ErrPhaseTimeout = errors.New("phase timed out")
+	// ErrIdleTimeout is returned (wrapped) when the agent produced no output for
+	// longer than the idle timeout, e.g. because it is blocked on an interactive prompt.
+	ErrIdleTimeout = errors.New("agent idle timeout")
+)
+
+// IsTimeout reports whether err is a phase timeout or an idle timeout, as opposed
+// to the agent exiting with an error on its own.
+func IsTimeout(err error) bool {
+	return errors.Is(err, ErrPhaseTimeout) || errors.Is(err, ErrIdleTimeout)
+}
+
+// watchIdle cancels the run with ErrIdleTimeout once buf has seen no output for idle.
+// It returns when ctx is done.
+func watchIdle(ctx context.Context, buf *lastLinesBuffer, idle time.Duration, cancel context.CancelCauseFunc) {
+	interval := min(max(idle/4, 10*time.Millisecond), 10*time.Second)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if buf.idleFor() >= idle {
+				cancel(ErrIdleTimeout)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,474 @@
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Transcript record types, in the order they appear in a transcript file.
+const (
+	RecordStart  = "start"
+	RecordOutput = "output"
+	RecordEnd    = "end"
+)
+
+// Phase results recorded in a transcript's end record.
+const (
+	ResultSuccess     = "success"
+	ResultFailed      = "failed"
+	ResultTimeout     = "timeout"
+	ResultInterrupted = "interrupted"
+
+	// ResultRunning is reported for transcripts that have no end record yet.
+	ResultRunning = "running"
+)
+
+// TranscriptRecord is one JSON line of a phase transcript. A transcript holds one
+// start record, an output record per line the agent printed, and an end record
+// once the phase finishes.
+type TranscriptRecord struct {
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+
+	// Start record.
+	Phase       string `json:"phase,omitempty"`
+	Story       string `json:"story,omitempty"`
+	Agent       string `json:"agent,omitempty"`
+	Model       string `json:"model,omitempty"`
+	ProjectRoot string `json:"project_root,omitempty"`
+	Prompt      string `json:"prompt,omitempty"`
+	PID         int    `json:"pid,omitempty"` // the runner process writing the transcript
+
+	// Output record. Lines that parse as JSON (stream-json events) are stored in
+	// Event; anything else is stored verbatim in Line.
+	Stream string          `json:"stream,omitempty"` // "stdout", "stderr" or "pty"
+	Line   string          `json:"line,omitempty"`
+	Event  json.RawMessage `json:"event,omitempty"`
+
+	// End record.
+	Result     string      `json:"result,omitempty"`
+	Failure    FailureKind `json:"failure,omitempty"`
+	ExitCode   *int        `json:"exit_code,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	DurationMS int64       `json:"duration_ms,omitempty"`
+}
+
+// Transcript writes a phase's full output to a JSONL file. A nil *Transcript
+// discards everything, so callers need not check whether transcripts are enabled.
+type Transcript struct {
+	Path string
+
+	mu      sync.Mutex
+	f       *os.File
+	enc     *json.Encoder
+	started time.Time
+}
+
+// maxTranscriptAttempts bounds the names createTranscript tries for one phase run.
+const maxTranscriptAttempts = 100
+
+// TranscriptFileName returns the file name for a phase run started at t:
+// <timestamp>-<story>-<phase>.jsonl, or <timestamp>-<phase>.jsonl without a story.
+// An attempt above 1 is appended as -<attempt>, for runs of the same phase that
+// start within the same second (quick retries and fallbacks).
+func TranscriptFileName(t time.Time, story, phase string, attempt int) string {
+	parts := []string{t.Format("20060102-150405")}
+	if story != "" {
+		parts = append(parts, story)
+	}
+	parts = append(parts, phase)
+	if attempt > 1 {
+		parts = append(parts, strconv.Itoa(attempt))
+	}
+	return strings.Join(parts, "-") + ".jsonl"
+}
+
+// createTranscript creates a transcript file in dir and writes its start record.
+func createTranscript(dir string, start TranscriptRecord) (*Transcript, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating transcript directory: %w", err)
+	}
+	for attempt := 1; ; attempt++ {
+		path := filepath.Join(dir, TranscriptFileName(start.Time, start.Story, start.Phase, attempt))
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if errors.Is(err, fs.ErrExist) && attempt < maxTranscriptAttempts {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("creating transcript: %w", err)
+		}
+		t := &Transcript{Path: path, f: f, enc: json.NewEncoder(f), started: start.Time}
+		start.Type = RecordStart
+		t.write(start)
+		return t, nil
+	}
+}
+
+func (t *Transcript) write(rec TranscriptRecord) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.f == nil {
+		return
+	}
+	_ = t.enc.Encode(rec)
+}
+
+// output records one line the agent printed on stream.
+func (t *Transcript) output(stream, line string) {
+	if t == nil {
+		return
+	}
+	rec := TranscriptRecord{Type: RecordOutput, Time: time.Now(), Stream: stream}
+	if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "{") && json.Valid([]byte(trimmed)) {
+		rec.Event = json.RawMessage(trimmed)
+	} else {
+		rec.Line = line
+	}
+	t.write(rec)
+}
+
+// finish writes the end record for the phase result err and closes the file.
+func (t *Transcript) finish(err error) {
+	if t == nil {
+		return
+	}
+	now := time.Now()
+	rec := TranscriptRecord{
+		Type:       RecordEnd,
+		Time:       now,
+		DurationMS: now.Sub(t.started).Milliseconds(),
+	}
+	rec.Result, rec.Failure, rec.ExitCode = phaseOutcome(err)
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	t.write(rec)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.f.Close()
+	t.f = nil
+}
+
+// phaseOutcome maps a phase error to the result, failure kind and exit code
+// recorded in transcripts and reports. The exit code is nil when the agent did
+// not get to exit on its own (it was not started, timed out or was interrupted).
+func phaseOutcome(err error) (result string, failure FailureKind, exitCode *int) {
+	failure = Classify(err)
+	switch failure {
+	case "":
+		result = ResultSuccess
+	case FailureTimeout:
+		result = ResultTimeout
+	case FailureInterrupted:
+		result = ResultInterrupted
+	default:
+		result = ResultFailed
+	}
+	var pe *PhaseError
+	if errors.As(err, &pe) {
+		code := pe.ExitCode
+		exitCode = &code
+	} else if err == nil {
+		code := 0
+		exitCode = &code
+	}
+	return result, failure, exitCode
+}
+
+// TranscriptSummary describes one transcript file without loading its output.
+type TranscriptSummary struct {
+	Path  string
+	Start TranscriptRecord
+	End   *TranscriptRecord // nil while the phase is still running (or if it was killed)
+}
+
+// Result returns the end record's result, or ResultRunning if there is none yet.
+func (s TranscriptSummary) Result() string {
+	if s.End == nil {
+		return ResultRunning
+	}
+	return s.End.Result
+}
+
+// Duration returns the recorded phase duration, or the time since start if unfinished.
+func (s TranscriptSummary) Duration() time.Duration {
+	if s.End == nil {
+		return time.Since(s.Start.Time)
+	}
+	return time.Duration(s.End.DurationMS) * time.Millisecond
+}
+
+// maxTranscriptLine bounds a single transcript line; start records carry the full prompt.
+const maxTranscriptLine = 16 * 1024 * 1024
+
+// endRecordWindow is how much of a transcript's tail is read to find its end record.
+const endRecordWindow = 64 * 1024
+
+// ListTranscripts summarises every transcript in dir, newest first.
+// A missing directory yields an empty list.
+func ListTranscripts(dir string) ([]TranscriptSummary, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	var summaries []TranscriptSummary
+	for _, p := range paths {
+		s, err := SummarizeTranscript(p)
+		if err != nil {
+			continue // not a transcript, or unreadable
+		}
+		summaries = append(summaries, s)
+	}
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].Start.Time.After(summaries[j].Start.Time)
+	})
+	return summaries, nil
+}
+
+// SummarizeTranscript reads a transcript's start record and, if present, its end record.
+func SummarizeTranscript(path string) (TranscriptSummary, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return TranscriptSummary{}, err
+	}
+	defer f.Close()
+
+	s := TranscriptSummary{Path: path}
+	first, err := bufio.NewReaderSize(f, 64*1024).ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return s, err
+	}
+	if err := json.Unmarshal(first, &s.Start); err != nil || s.Start.Type != RecordStart {
+		return s, fmt.Errorf("%s: not a transcript", path)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return s, err
+	}
+	offset := info.Size() - endRecordWindow
+	if offset < 0 {
+		offset = 0
+	}
+	tail := make([]byte, info.Size()-offset)
+	if _, err := f.ReadAt(tail, offset); err != nil && err != io.EOF {
+		return s, err
+	}
+	lines := bytes.Split(bytes.TrimRight(tail, "\n"), []byte("\n"))
+	var last TranscriptRecord
+	if json.Unmarshal(lines[len(lines)-1], &last) == nil && last.Type == RecordEnd {
+		s.End = &last
+	}
+	return s, nil
+}
+
+// ReadTranscript loads every record of a transcript. A trailing partial line
+// (from a phase that is still being written) is ignored.
+func ReadTranscript(path string) ([]TranscriptRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []TranscriptRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxTranscriptLine)
+	for scanner.Scan() {
+		var rec TranscriptRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			continue
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return records, fmt.Errorf("reading transcript %s: %w", path, err)
+	}
+	return records, nil
+}
+
+// ErrTranscriptAbandoned is returned by FollowTranscript for a transcript that
+// will never get its end record, because the runner writing it is gone.
+var ErrTranscriptAbandoned = errors.New("transcript abandoned without an end record")
+
+// FollowTranscript calls fn for every record of the transcript at path, then keeps
+// polling for appended records until the end record arrives or ctx is done. It
+// gives up with ErrTranscriptAbandoned once the runner process recorded in the
+// start record has exited or, for a transcript without one, once nothing has
+// been appended for idle (if positive) and a newer transcript exists.
+func FollowTranscript(ctx context.Context, path string, poll, idle time.Duration, fn func(TranscriptRecord)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	rd := bufio.NewReaderSize(f, 64*1024)
+	var partial []byte
+	pid, last := 0, time.Now()
+	for {
+		chunk, err := rd.ReadBytes('\n')
+		partial = append(partial, chunk...)
+		if err == nil {
+			var rec TranscriptRecord
+			if json.Unmarshal(partial, &rec) == nil {
+				fn(rec)
+				if rec.Type == RecordStart {
+					pid = rec.PID
+				}
+				if rec.Type == RecordEnd {
+					return nil
+				}
+			}
+			partial = partial[:0]
+			last = time.Now()
+			continue
+		}
+		if err != io.EOF {
+			return err
+		}
+		if abandoned(path, pid, time.Since(last), idle) {
+			return fmt.Errorf("%s: %w", path, ErrTranscriptAbandoned)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(poll):
+		}
+	}
+}
+
+// FollowEvent is a record of one of the transcripts FollowTranscripts follows,
+// or, with Err set, why following that transcript stopped before its end record.
+type FollowEvent struct {
+	Path   string
+	Record TranscriptRecord
+	Err    error
+}
+
+// FollowTranscripts follows every transcript in dir that is still running or
+// that starts later, like FollowTranscript, until ctx is done. keep, if not nil,
+// selects the transcripts to follow. Records of concurrent phases (run auto
+// --parallel) interleave; fn is called from one goroutine at a time.
+func FollowTranscripts(ctx context.Context, dir string, poll, idle time.Duration, keep func(TranscriptSummary) bool, fn func(FollowEvent)) error {
+	since := time.Now()
+	events := make(chan FollowEvent)
+	var followers sync.WaitGroup
+	defer followers.Wait()
+
+	followed := map[string]bool{}
+	for {
+		runs, err := ListTranscripts(dir)
+		if err != nil {
+			return err
+		}
+		for i := len(runs) - 1; i >= 0; i-- { // oldest first
+			run := runs[i]
+			if followed[run.Path] || run.End != nil && run.Start.Time.Before(since) || keep != nil && !keep(run) {
+				continue
+			}
+			followed[run.Path] = true
+			followers.Add(1)
+			go func() {
+				defer followers.Done()
+				send := func(ev FollowEvent) {
+					select {
+					case events <- ev:
+					case <-ctx.Done():
+					}
+				}
+				err := FollowTranscript(ctx, run.Path, poll, idle, func(rec TranscriptRecord) {
+					send(FollowEvent{Path: run.Path, Record: rec})
+				})
+				if err != nil && ctx.Err() == nil {
+					send(FollowEvent{Path: run.Path, Err: err})
+				}
+			}()
+		}
+
+		rescan := time.After(4 * poll)
+	wait:
+		for {
+			select {
+			case ev := <-events:
+				fn(ev)
+			case <-rescan:
+				break wait
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// abandoned reports whether the transcript at path, written by pid (0 if not
+// recorded) and quiet for the given time, will not be finished.
+func abandoned(path string, pid int, quiet, idle time.Duration) bool {
+	if pid > 0 {
+		return !processAlive(pid)
+	}
+	return idle > 0 && quiet >= idle && newerTranscript(path)
+}
+
+// newerTranscript reports whether another transcript next to path was written
+// to after it.
+func newerTranscript(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	others, _ := filepath.Glob(filepath.Join(filepath.Dir(path), "*.jsonl"))
+	for _, other := range others {
+		if o, err := os.Stat(other); err == nil && !os.SameFile(info, o) && o.ModTime().After(info.ModTime()) {
+			return true
+		}
+	}
+	return false
+}
+
+// RecordFormatter renders output records the way the live display shows them:
+// tool calls as "Reading path", "Running: cmd", etc., and plain lines as-is.
+// Use one formatter per transcript, since decoders may keep state across lines.
+type RecordFormatter struct {
+	dec LineDecoder
+}
+
+// NewRecordFormatter returns a formatter for a transcript recorded with agentType.
+func NewRecordFormatter(agentType string) *RecordFormatter {
+	return &RecordFormatter{dec: newDecoder(agentType)}
+}
+
+// Format returns the display lines for rec. Non-output records and events with
+// nothing to show yield no lines.
+func (f *RecordFormatter) Format(rec TranscriptRecord) []string {
+	if rec.Type != RecordOutput {
+		return nil
+	}
+	if rec.Event == nil {
+		if line := strings.TrimSpace(rec.Line); line != "" {
+			return []string{line}
+		}
+		return nil
+	}
+	if f.dec == nil {
+		return []string{string(rec.Event)}
+	}
+	return f.dec.Decode(rec.Event)
+}
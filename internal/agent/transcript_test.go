@@ -0,0 +1,303 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTranscriptRoundTrip(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	older, err := createTranscript(dir, TranscriptRecord{Time: start, Story: "1-1-login", Phase: "create-story", Agent: "claude-code", Model: "sonnet"})
+	if err != nil {
+		t.Fatalf("createTranscript: %v", err)
+	}
+	older.output("stdout", `{"type":"assistant","message":{"content":[{"type":"tool_use","name":"Read","input":{"file_path":"a.go"}}]}}`)
+	older.output("stderr", "warning: slow")
+	older.finish(nil)
+
+	newer, err := createTranscript(dir, TranscriptRecord{Time: start.Add(time.Minute), Story: "1-1-login", Phase: "dev-story"})
+	if err != nil {
+		t.Fatalf("createTranscript: %v", err)
+	}
+	newer.output("stdout", "working")
+	// Left running: no finish.
+
+	runs, err := ListTranscripts(dir)
+	if err != nil {
+		t.Fatalf("ListTranscripts: %v", err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("got %d runs, want 2", len(runs))
+	}
+	if runs[0].Start.Phase != "dev-story" || runs[0].Result() != ResultRunning {
+		t.Errorf("runs[0] = %s/%s, want dev-story/running", runs[0].Start.Phase, runs[0].Result())
+	}
+	if runs[1].Start.Phase != "create-story" || runs[1].Result() != ResultSuccess {
+		t.Errorf("runs[1] = %s/%s, want create-story/success", runs[1].Start.Phase, runs[1].Result())
+	}
+	if runs[1].End.ExitCode == nil || *runs[1].End.ExitCode != 0 {
+		t.Errorf("runs[1] exit code = %v, want 0", runs[1].End.ExitCode)
+	}
+
+	records, err := ReadTranscript(runs[1].Path)
+	if err != nil {
+		t.Fatalf("ReadTranscript: %v", err)
+	}
+	var lines []string
+	formatter := NewRecordFormatter(runs[1].Start.Agent)
+	for _, rec := range records {
+		lines = append(lines, formatter.Format(rec)...)
+	}
+	want := []string{"Reading a.go", "warning: slow"}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("formatted lines = %q, want %q", lines, want)
+	}
+	newer.finish(nil)
+}
+
+func TestTranscriptFileName(t *testing.T) {
+	t.Parallel()
+	at := time.Date(2026, 3, 1, 10, 4, 5, 0, time.UTC)
+	tests := []struct {
+		story, phase string
+		attempt      int
+		want         string
+	}{
+		{"1-1-login", "dev-story", 1, "20260301-100405-1-1-login-dev-story.jsonl"},
+		{"", "correct-course", 1, "20260301-100405-correct-course.jsonl"},
+		{"1-1-login", "dev-story", 2, "20260301-100405-1-1-login-dev-story-2.jsonl"},
+	}
+	for _, tt := range tests {
+		if got := TranscriptFileName(at, tt.story, tt.phase, tt.attempt); got != tt.want {
+			t.Errorf("TranscriptFileName(%q, %q, %d) = %q, want %q", tt.story, tt.phase, tt.attempt, got, tt.want)
+		}
+	}
+}
+
+// Quick retries of a phase start within the same second; each gets its own file.
+func TestCreateTranscriptSameSecond(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	start := TranscriptRecord{Time: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC), Story: "1-1-login", Phase: "dev-story"}
+
+	seen := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		tr, err := createTranscript(dir, start)
+		if err != nil {
+			t.Fatalf("createTranscript #%d: %v", i+1, err)
+		}
+		if seen[tr.Path] {
+			t.Errorf("createTranscript #%d reused %s", i+1, tr.Path)
+		}
+		seen[tr.Path] = true
+		tr.finish(nil)
+	}
+	runs, err := ListTranscripts(dir)
+	if err != nil {
+		t.Fatalf("ListTranscripts: %v", err)
+	}
+	if len(runs) != 3 {
+		t.Errorf("got %d runs, want 3", len(runs))
+	}
+}
+
+func TestListTranscriptsSkipsOtherFiles(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "notes.jsonl"), []byte("{\"foo\":1}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runs, err := ListTranscripts(dir)
+	if err != nil {
+		t.Fatalf("ListTranscripts: %v", err)
+	}
+	if len(runs) != 0 {
+		t.Errorf("got %d runs, want 0", len(runs))
+	}
+	if runs, err := ListTranscripts(filepath.Join(dir, "missing")); err != nil || len(runs) != 0 {
+		t.Errorf("missing dir: runs=%v err=%v, want none", runs, err)
+	}
+}
+
+func TestFollowTranscript(t *testing.T) {
+	t.Parallel()
+	tr, err := createTranscript(t.TempDir(), TranscriptRecord{Time: time.Now(), Phase: "dev-story"})
+	if err != nil {
+		t.Fatalf("createTranscript: %v", err)
+	}
+
+	got := make(chan string, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- FollowTranscript(context.Background(), tr.Path, 5*time.Millisecond, 0, func(rec TranscriptRecord) {
+			got <- rec.Type
+		})
+	}()
+
+	if typ := <-got; typ != RecordStart {
+		t.Fatalf("first record = %q, want start", typ)
+	}
+	tr.output("stdout", "hello")
+	if typ := <-got; typ != RecordOutput {
+		t.Fatalf("second record = %q, want output", typ)
+	}
+	tr.finish(errors.New("boom"))
+	if typ := <-got; typ != RecordEnd {
+		t.Fatalf("third record = %q, want end", typ)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("FollowTranscript: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("FollowTranscript did not return after the end record")
+	}
+}
+
+func TestFollowTranscriptAbandoned(t *testing.T) {
+	t.Parallel()
+	// A runner that has exited: its pid no longer names a process.
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("running helper process: %v", err)
+	}
+	deadPID := cmd.Process.Pid
+
+	tests := []struct {
+		name  string
+		pid   int
+		idle  time.Duration
+		newer bool // another transcript is written after this one
+	}{
+		{"runner exited", deadPID, 0, false},
+		{"idle with a newer transcript", 0, 20 * time.Millisecond, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			dir := t.TempDir()
+			start := time.Now()
+			tr, err := createTranscript(dir, TranscriptRecord{Time: start, Phase: "dev-story", PID: tt.pid})
+			if err != nil {
+				t.Fatalf("createTranscript: %v", err)
+			}
+			tr.output("stdout", "working")
+			// Killed: no finish.
+			if tt.newer {
+				next, err := createTranscript(dir, TranscriptRecord{Time: start.Add(time.Second), Phase: "code-review"})
+				if err != nil {
+					t.Fatalf("createTranscript: %v", err)
+				}
+				later := time.Now().Add(time.Second)
+				if err := os.Chtimes(next.Path, later, later); err != nil {
+					t.Fatal(err)
+				}
+				defer next.finish(nil)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			var records int
+			err = FollowTranscript(ctx, tr.Path, 5*time.Millisecond, tt.idle, func(TranscriptRecord) { records++ })
+			if !errors.Is(err, ErrTranscriptAbandoned) {
+				t.Errorf("FollowTranscript() error = %v, want ErrTranscriptAbandoned", err)
+			}
+			if records != 2 {
+				t.Errorf("got %d records, want 2", records)
+			}
+		})
+	}
+}
+
+// A silent transcript of a live runner is still followed.
+func TestFollowTranscriptLiveRunner(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	tr, err := createTranscript(dir, TranscriptRecord{Time: time.Now(), Phase: "dev-story", PID: os.Getpid()})
+	if err != nil {
+		t.Fatalf("createTranscript: %v", err)
+	}
+	defer tr.finish(nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err = FollowTranscript(ctx, tr.Path, 5*time.Millisecond, time.Millisecond, func(TranscriptRecord) {})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("FollowTranscript() error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+// Under run auto --parallel several phases write transcripts at once; all of them
+// are followed, and so is one that starts later.
+func TestFollowTranscripts(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	now := time.Now()
+	create := func(story string, at time.Time) *Transcript {
+		tr, err := createTranscript(dir, TranscriptRecord{Time: at, Story: story, Phase: "dev-story", PID: os.Getpid()})
+		if err != nil {
+			t.Fatalf("createTranscript: %v", err)
+		}
+		return tr
+	}
+	finished := create("1-0-old", now.Add(-time.Hour))
+	finished.finish(nil)
+	a, b := create("1-1-a", now.Add(-time.Minute)), create("1-2-b", now.Add(-time.Minute))
+	skipped := create("1-9-skip", now.Add(-time.Minute))
+	defer skipped.finish(nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	lines := make(chan string, 20)
+	done := make(chan error, 1)
+	go func() {
+		keep := func(s TranscriptSummary) bool { return s.Start.Story != "1-9-skip" }
+		done <- FollowTranscripts(ctx, dir, 5*time.Millisecond, 0, keep, func(ev FollowEvent) {
+			if ev.Err != nil {
+				t.Errorf("following %s: %v", ev.Path, ev.Err)
+				return
+			}
+			rec, _ := SummarizeTranscript(ev.Path)
+			if ev.Record.Type == RecordOutput {
+				lines <- rec.Start.Story + ": " + ev.Record.Line
+			}
+		})
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	a.output("stdout", "a1")
+	b.output("stdout", "b1")
+	skipped.output("stdout", "nope")
+	c := create("1-3-c", time.Now())
+	c.output("stdout", "c1")
+	a.finish(nil)
+	b.finish(nil)
+	c.finish(nil)
+
+	want := map[string]bool{"1-1-a: a1": true, "1-2-b: b1": true, "1-3-c: c1": true}
+	for len(want) > 0 {
+		select {
+		case line := <-lines:
+			if !want[line] {
+				t.Errorf("unexpected line %q", line)
+			}
+			delete(want, line)
+		case <-ctx.Done():
+			t.Fatalf("lines not followed: %v", want)
+		}
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("FollowTranscripts() = %v, want context.Canceled", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 )
 
 // Config holds the runner configuration
@@ -45,6 +46,31 @@ const (
 	AgentTypeOpenCode    = "opencode"
 )
 
+// agentBinaries maps each registered agent type to the binary names LookupAgent
+// tries, in order. Backends register theirs with RegisterAgent.
+var agentBinaries = map[string][]string{}
+
+// AgentBinaries returns the binary names for agentType, falling back to
+// cursor-agent's if agentType is unrecognised.
+func AgentBinaries(agentType string) []string {
+	if names, ok := agentBinaries[agentType]; ok {
+		return names
+	}
+	return agentBinaries[AgentTypeCursorAgent]
+}
+
+// RegisterAgent adds or replaces an agent type's binary names and default models
+// (see DefaultModels). Each agent backend calls it while registering itself, so
+// it runs during initialisation, before any lookups.
+func RegisterAgent(agentType string, binaries []string, models map[string]string) {
+	if len(binaries) > 0 {
+		agentBinaries[agentType] = binaries
+	}
+	if len(models) > 0 {
+		DefaultModels[agentType] = models
+	}
+}
+
 // LookupAgent looks for the appropriate agent binary based on agentType.
 // If agentPath is non-empty, it is returned as-is.
 func LookupAgent(agentPath string, agentType string) (string, error) {
@@ -52,37 +78,14 @@ func LookupAgent(agentPath string, agentType string) (string, error) {
 		return agentPath, nil
 	}
 
-	var names []string
-	switch agentType {
-	case AgentTypeClaudeCode:
-		names = []string{"claude"}
-	case AgentTypeGeminiCLI:
-		names = []string{"gemini"}
-	case AgentTypeOpenCode:
-		names = []string{"opencode"}
-	case AgentTypeCursorAgent:
-		fallthrough
-	default:
-		names = []string{"cursor-agent", "agent"}
-	}
-
+	names := AgentBinaries(agentType)
 	for _, name := range names {
 		path, err := execLookPath(name)
 		if err == nil {
 			return path, nil
 		}
 	}
-
-	switch agentType {
-	case AgentTypeClaudeCode:
-		return "", fmt.Errorf("claude not found in PATH")
-	case AgentTypeGeminiCLI:
-		return "", fmt.Errorf("gemini not found in PATH")
-	case AgentTypeOpenCode:
-		return "", fmt.Errorf("opencode not found in PATH")
-	default:
-		return "", fmt.Errorf("cursor-agent or agent not found in PATH")
-	}
+	return "", fmt.Errorf("%s not found in PATH", strings.Join(names, " or "))
 }
 
 // execLookPath checks common locations and PATH for the named binary.
@@ -2,9 +2,11 @@ package config
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestResolveProjectRoot(t *testing.T) {
@@ -71,6 +73,24 @@ func TestResolveProjectRoot(t *testing.T) {
 	}
 }
 
+// The agent backends register the real binaries and model tables; these fixtures
+// stand in for them so the lookups can be tested on their own.
+func TestMain(m *testing.M) {
+	RegisterAgent(AgentTypeCursorAgent, []string{"cursor-agent", "agent"}, map[string]string{
+		"create-story": "cursor-create",
+		"default":      "cursor-default",
+		"fallback":     "cursor-fallback",
+	})
+	RegisterAgent(AgentTypeClaudeCode, []string{"claude"}, map[string]string{
+		"dev-story": "claude-dev",
+		"default":   "claude-default",
+		"fallback":  "claude-fallback",
+	})
+	RegisterAgent(AgentTypeGeminiCLI, []string{"gemini"}, map[string]string{"default": "gemini-default"})
+	RegisterAgent(AgentTypeOpenCode, []string{"opencode"}, map[string]string{"default": "opencode-default"})
+	os.Exit(m.Run())
+}
+
 func TestDefaultModel(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -78,19 +98,13 @@ func TestDefaultModel(t *testing.T) {
 		phase     string
 		want      string
 	}{
-		{AgentTypeCursorAgent, "create-story", "claude-4.6-sonnet-medium"},
-		{AgentTypeClaudeCode, "dev-story", "haiku"},
-		{AgentTypeGeminiCLI, "code-review", "gemini-3-pro"},
-		{AgentTypeCursorAgent, "nonexistent-phase", "composer-1.5"},
-		{"unknown-agent", "create-story", "claude-4.6-sonnet-medium"},
-		{"unknown-agent", "nonexistent", "composer-1.5"},
-		{AgentTypeOpenCode, "create-story", "opencode-go/kimi-k2.5"},
-		{AgentTypeOpenCode, "dev-story", "opencode-go/minimax-m2.5"},
-		{AgentTypeOpenCode, "code-review", "opencode-go/kimi-k2.5"},
-		{AgentTypeOpenCode, "retrospective", "opencode-go/kimi-k2.5"},
-		{AgentTypeOpenCode, "correct-course", "opencode-go/glm-5"},
-		{AgentTypeOpenCode, "sprint-planning", "opencode-go/glm-5"},
-		{AgentTypeOpenCode, "nonexistent-phase", "opencode-go/kimi-k2.5"},
+		{AgentTypeCursorAgent, "create-story", "cursor-create"},
+		{AgentTypeCursorAgent, "nonexistent-phase", "cursor-default"},
+		{AgentTypeClaudeCode, "dev-story", "claude-dev"},
+		{AgentTypeClaudeCode, "code-review", "claude-default"},
+		{AgentTypeGeminiCLI, "code-review", "gemini-default"},
+		{"unknown-agent", "create-story", "cursor-create"},
+		{"unknown-agent", "nonexistent", "cursor-default"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.agentType+"_"+tt.phase, func(t *testing.T) {
@@ -234,3 +248,388 @@ func TestLookupAgent(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckIdleTimeout(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		d       time.Duration
+		wantErr bool
+	}{
+		{0, false},
+		{MinIdleTimeout, false},
+		{10 * time.Minute, false},
+		{time.Nanosecond, true},
+		{MinIdleTimeout - time.Millisecond, true},
+		{-time.Minute, true},
+	}
+	for _, tt := range tests {
+		if err := CheckIdleTimeout(tt.d); (err != nil) != tt.wantErr {
+			t.Errorf("CheckIdleTimeout(%s) error = %v, wantErr %v", tt.d, err, tt.wantErr)
+		}
+	}
+}
+
+func TestParsePhaseTimeouts(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		specs   []string
+		want    map[string]time.Duration
+		wantErr string
+	}{
+		{
+			name:  "equals and colon forms",
+			specs: []string{"dev-story=90m", "code-review: 20m"},
+			want:  map[string]time.Duration{"dev-story": 90 * time.Minute, "code-review": 20 * time.Minute},
+		},
+		{
+			name:  "bare duration sets default",
+			specs: []string{"2h", "dev-story=3h"},
+			want:  map[string]time.Duration{"default": 2 * time.Hour, "dev-story": 3 * time.Hour},
+		},
+		{
+			name:  "empty specs ignored",
+			specs: []string{"", "  "},
+			want:  map[string]time.Duration{},
+		},
+		{
+			name:    "bad duration",
+			specs:   []string{"dev-story=soon"},
+			wantErr: "invalid phase timeout",
+		},
+		{
+			name:    "missing phase",
+			specs:   []string{"=10m"},
+			wantErr: "missing phase name",
+		},
+		{
+			name:    "negative duration",
+			specs:   []string{"dev-story=-1m"},
+			wantErr: "must not be negative",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParsePhaseTimeouts(tt.specs)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParsePhaseTimeouts(%q) error = %v, want containing %q", tt.specs, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for phase, d := range tt.want {
+				if got[phase] != d {
+					t.Errorf("timeout[%q] = %v, want %v", phase, got[phase], d)
+				}
+			}
+		})
+	}
+}
+
+func TestParsePhaseAgents(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		specs   []string
+		want    map[string]PhaseAgent
+		wantErr string
+	}{
+		{
+			name:  "agent and agent with model",
+			specs: []string{"code-review=gemini-cli", "dev-story = claude-code:opus"},
+			want: map[string]PhaseAgent{
+				"code-review": {AgentType: "gemini-cli"},
+				"dev-story":   {AgentType: "claude-code", Model: "opus"},
+			},
+		},
+		{
+			name:  "model keeps later colons",
+			specs: []string{"dev-story=custom:ollama:qwen3"},
+			want:  map[string]PhaseAgent{"dev-story": {AgentType: "custom", Model: "ollama:qwen3"}},
+		},
+		{
+			name:  "later spec wins",
+			specs: []string{"code-review=gemini-cli", "code-review=opencode", ""},
+			want:  map[string]PhaseAgent{"code-review": {AgentType: "opencode"}},
+		},
+		{
+			name:    "missing phase",
+			specs:   []string{"gemini-cli"},
+			wantErr: "want phase=agent[:model]",
+		},
+		{
+			name:    "missing agent",
+			specs:   []string{"code-review=:gemini-3-pro"},
+			wantErr: "missing agent type",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParsePhaseAgents(tt.specs)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParsePhaseAgents(%q) error = %v, want containing %q", tt.specs, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for phase, p := range tt.want {
+				if got[phase] != p {
+					t.Errorf("agent[%q] = %+v, want %+v", phase, got[phase], p)
+				}
+			}
+		})
+	}
+}
+
+func TestFallbackModel(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		agentType string
+		current   string
+		want      string
+	}{
+		{AgentTypeClaudeCode, "claude-default", "claude-fallback"},
+		{AgentTypeClaudeCode, "claude-fallback", "claude-default"},
+		{AgentTypeGeminiCLI, "gemini-default", ""},
+		{AgentTypeGeminiCLI, "other", "gemini-default"},
+		{"unknown-agent", "cursor-default", "cursor-fallback"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.agentType+"_"+tt.current, func(t *testing.T) {
+			t.Parallel()
+			got := FallbackModel(tt.agentType, tt.current)
+			if got != tt.want {
+				t.Errorf("FallbackModel(%q, %q) = %q, want %q", tt.agentType, tt.current, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	tests := []struct {
+		name    string
+		path    string
+		want    int
+		wantErr string
+	}{
+		{
+			name: "missing file is empty",
+			path: filepath.Join(dir, "nope.yaml"),
+		},
+		{
+			name: "valid backend",
+			path: write("ok.yaml", `
+backends:
+  - name: codex
+    binary: codex
+    args: [exec, --model, "{model}", "{prompt}"]
+    output: {format: json, text: msg.text}
+    models: {default: gpt-5}
+`),
+			want: 1,
+		},
+		{
+			name:    "missing prompt placeholder",
+			path:    write("noprompt.yaml", "backends:\n  - {name: x, binary: x, args: [run]}\n"),
+			wantErr: "{prompt} or {prompt_file}",
+		},
+		{
+			name:    "model placeholder without default model",
+			path:    write("nomodel.yaml", "backends:\n  - {name: x, binary: x, args: ['{model}', '{prompt}']}\n"),
+			wantErr: "models.default is required",
+		},
+		{
+			name:    "unknown output format",
+			path:    write("badfmt.yaml", "backends:\n  - {name: x, binary: x, args: ['{prompt}'], output: {format: xml}}\n"),
+			wantErr: "unknown output format",
+		},
+		{
+			name:    "json format without paths",
+			path:    write("nopaths.yaml", "backends:\n  - {name: x, binary: x, args: ['{prompt}'], output: {format: json}}\n"),
+			wantErr: "at least one of text, tool or error",
+		},
+		{
+			name:    "phase agent without agent type",
+			path:    write("badphase.yaml", "phase_agents:\n  code-review: ':gemini-3-pro'\n"),
+			wantErr: "phase_agents.code-review",
+		},
+		{
+			name:    "invalid yaml",
+			path:    write("bad.yaml", "backends: [\n"),
+			wantErr: "parsing",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			f, err := LoadFile(tt.path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadFile error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(f.Backends) != tt.want {
+				t.Errorf("got %d backends, want %d", len(f.Backends), tt.want)
+			}
+		})
+	}
+}
+
+func TestResolver(t *testing.T) {
+	projectRoot := t.TempDir()
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+
+	project := `
+agent_type: claude-code
+prime_directive: docs/pd.md
+models:
+  resolver-agent:
+    dev-story: project-dev
+phase_agents:
+  code-review: gemini-cli
+auto:
+  max_iterations: 10
+backends:
+  - {name: shared, binary: project-bin, args: ['{prompt}']}
+`
+	user := `
+agent_type: gemini-cli
+agent_path: /opt/agent
+models:
+  resolver-agent:
+    dev-story: user-dev
+    code-review: user-review
+phase_agents:
+  code-review: opencode
+  dev-story: claude-code:opus
+auto:
+  max_retries: 4
+  retry_backoff: 1m
+backends:
+  - {name: shared, binary: user-bin, args: ['{prompt}']}
+  - {name: mine, binary: mine, args: ['{prompt}']}
+`
+	if err := os.WriteFile(filepath.Join(projectRoot, FileName), []byte(project), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(xdg, "bmad-runner"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(xdg, "bmad-runner", UserFileName), []byte(user), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := LoadResolver(projectRoot)
+	if err != nil {
+		t.Fatalf("LoadResolver: %v", err)
+	}
+
+	settings := []struct {
+		key    string
+		want   string
+		source Source
+	}{
+		{KeyAgentType, "claude-code", SourceProject},
+		{KeyAgentPath, "/opt/agent", SourceUser},
+		{KeyPrimeDirective, filepath.Join(projectRoot, "docs/pd.md"), SourceProject},
+		{KeyMaxIterations, "10", SourceProject},
+		{KeyMaxRetries, "4", SourceUser},
+		{KeyRetryBackoff, "1m", SourceUser},
+	}
+	for _, s := range settings {
+		v, layer, ok := r.Lookup(s.key)
+		if !ok || v != s.want || layer.Source != s.source {
+			t.Errorf("Lookup(%q) = %q from %q (ok=%v), want %q from %q", s.key, v, layer.Source, ok, s.want, s.source)
+		}
+	}
+	if _, _, ok := r.Lookup(KeyModel); ok {
+		t.Errorf("Lookup(model) should be unset")
+	}
+
+	models := r.Models("resolver-agent")
+	if models["dev-story"].Model != "project-dev" || models["code-review"].Model != "user-review" {
+		t.Errorf("Models = %+v, want project dev-story and user code-review", models)
+	}
+	r.ApplyModels()
+	if got := DefaultModel("resolver-agent", "dev-story"); got != "project-dev" {
+		t.Errorf("DefaultModel after ApplyModels = %q, want project-dev", got)
+	}
+
+	phaseAgents := r.PhaseAgents()
+	if got := phaseAgents["code-review"]; got.AgentType != "gemini-cli" || got.Layer.Source != SourceProject {
+		t.Errorf("PhaseAgents()[code-review] = %+v, want gemini-cli from project", got)
+	}
+	if got := phaseAgents["dev-story"]; got.PhaseAgent.String() != "claude-code:opus" || got.Layer.Source != SourceUser {
+		t.Errorf("PhaseAgents()[dev-story] = %+v, want claude-code:opus from user", got)
+	}
+
+	backends := r.Backends()
+	if len(backends) != 2 || backends[0].Name != "shared" || backends[0].Binary != "project-bin" || backends[1].Name != "mine" {
+		t.Errorf("Backends = %+v, want shared from project and mine from user", backends)
+	}
+}
+
+func TestEnvVar(t *testing.T) {
+	t.Parallel()
+	tests := map[string]string{
+		"agent-type":     "BMAD_RUNNER_AGENT_TYPE",
+		"max-iterations": "BMAD_RUNNER_MAX_ITERATIONS",
+		"pty":            "BMAD_RUNNER_PTY",
+	}
+	for name, want := range tests {
+		if got := EnvVar(name); got != want {
+			t.Errorf("EnvVar(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestEnvModels(t *testing.T) {
+	t.Setenv("BMAD_RUNNER_MODEL_DEV_STORY", "env-dev")
+	t.Setenv("BMAD_RUNNER_FALLBACK_MODEL", " env-fallback ")
+	t.Setenv("BMAD_RUNNER_MODEL_FALLBACK", "true") // the --model-fallback flag
+	t.Setenv("BMAD_RUNNER_MODEL_CODE_REVIEW", "")
+	t.Setenv("BMAD_RUNNER_MODEL", "not-per-phase")
+
+	got := EnvModels()
+	want := map[string]string{"dev-story": "env-dev", "fallback": "env-fallback"}
+	if len(got) != len(want) {
+		t.Fatalf("EnvModels() = %+v, want phases %v", got, want)
+	}
+	for phase, model := range want {
+		o, name := got[phase], EnvVar("model-"+phase)
+		if phase == "fallback" {
+			name = EnvFallbackModel
+		}
+		if o.Model != model || o.Layer.Source != SourceEnv || o.Layer.Path != name {
+			t.Errorf("EnvModels()[%q] = %+v, want %q from %s", phase, o, model, name)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// EnvPrefix prefixes every environment variable the runner reads. Each CLI flag
+// has a BMAD_RUNNER_<FLAG> variable, e.g. BMAD_RUNNER_AGENT_TYPE for --agent-type.
+const EnvPrefix = "BMAD_RUNNER_"
+
+// EnvModelPrefix prefixes the per-phase model variables, e.g.
+// BMAD_RUNNER_MODEL_DEV_STORY sets the dev-story model.
+const EnvModelPrefix = EnvPrefix + "MODEL_"
+
+// EnvFallbackModel sets the "fallback" model of every backend. It is not
+// BMAD_RUNNER_MODEL_FALLBACK, which is the variable of the --model-fallback flag.
+const EnvFallbackModel = EnvPrefix + "FALLBACK_MODEL"
+
+// flagModelVars are the variables under EnvModelPrefix that belong to flags
+// rather than name a phase.
+var flagModelVars = map[string]bool{
+	EnvVar("model-fallback"): true,
+}
+
+// EnvVar returns the environment variable for the flag or phase name,
+// e.g. "max-iterations" -> BMAD_RUNNER_MAX_ITERATIONS.
+func EnvVar(name string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// EnvModels returns the per-phase models set by BMAD_RUNNER_MODEL_<PHASE>
+// variables, keyed by phase, and the fallback model set by EnvFallbackModel.
+// The layer path is the variable name.
+func EnvModels() map[string]ModelOverride {
+	out := map[string]ModelOverride{}
+	for _, kv := range os.Environ() {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || strings.TrimSpace(value) == "" {
+			continue
+		}
+		var phase string
+		switch {
+		case name == EnvFallbackModel:
+			phase = "fallback"
+		case strings.HasPrefix(name, EnvModelPrefix) && !flagModelVars[name]:
+			phase = strings.ToLower(strings.ReplaceAll(strings.TrimPrefix(name, EnvModelPrefix), "_", "-"))
+		}
+		if phase == "" {
+			continue
+		}
+		out[phase] = ModelOverride{Model: strings.TrimSpace(value), Layer: Layer{Source: SourceEnv, Path: name}}
+	}
+	return out
+}
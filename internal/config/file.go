@@ -0,0 +1,185 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the project-level runner config file, read from the project root.
+const FileName = ".bmad-runner.yaml"
+
+// UserFileName is the user-level runner config file, read from
+// $XDG_CONFIG_HOME/bmad-runner (~/.config/bmad-runner by default).
+const UserFileName = "config.yaml"
+
+// Output formats a custom backend can declare.
+const (
+	OutputPlain            = "plain"              // plain text lines, shown as-is
+	OutputClaudeStreamJSON = "claude-stream-json" // claude-code --output-format stream-json events
+	OutputJSON             = "json"               // JSONL events mapped with OutputSpec paths
+)
+
+// File is the contents of a runner config file. Unset fields leave the value to
+// a lower-precedence file or the built-in default.
+type File struct {
+	AgentType      string `yaml:"agent_type"`
+	AgentPath      string `yaml:"agent_path"`
+	Model          string `yaml:"model"`
+	PrimeDirective string `yaml:"prime_directive"`
+
+	// Models overrides DefaultModels per agent type: agent -> phase -> model,
+	// including the "default" and "fallback" keys.
+	Models map[string]map[string]string `yaml:"models"`
+
+	// PhaseAgents runs phases on other backends: phase -> "agent" or "agent:model".
+	PhaseAgents map[string]string `yaml:"phase_agents"`
+
+	// Auto holds run auto loop limits.
+	Auto AutoSettings `yaml:"auto"`
+
+	// Backends declares additional agent backends, selectable with --agent-type.
+	Backends []BackendSpec `yaml:"backends"`
+}
+
+// AutoSettings are the run auto limits a config file can set.
+type AutoSettings struct {
+	MaxIterations *int   `yaml:"max_iterations"`
+	MaxNewEpics   *int   `yaml:"max_new_epics"`
+	MaxRetries    *int   `yaml:"max_retries"`
+	RetryBackoff  string `yaml:"retry_backoff"`
+}
+
+// BackendSpec declares a custom agent backend, e.g. a wrapper script or a CLI
+// bmad-runner has no built-in support for.
+type BackendSpec struct {
+	Name string `yaml:"name"`
+	// Binary is looked up in PATH (or ~/.local/bin) unless --agent-path is given.
+	Binary string `yaml:"binary"`
+	// Args is the argv template. Each element may contain the placeholders {model},
+	// {prompt}, {prompt_file} (a temporary file holding the prompt) and {workspace}
+	// (the project root).
+	Args []string `yaml:"args"`
+	// CommandDir is the project-relative directory holding bmad-bmm-<phase>.md
+	// command files. Defaults to .cursor/commands.
+	CommandDir string `yaml:"command_dir"`
+	// Output says how to read the agent's stdout for the live display.
+	Output OutputSpec `yaml:"output"`
+	// Models maps phases to models, with "default" and "fallback" keys as in
+	// DefaultModels.
+	Models map[string]string `yaml:"models"`
+}
+
+// OutputSpec describes a custom backend's stdout. For the json format, each field
+// is a dot path into an event (e.g. "part.text" or "message.content.0.text");
+// events are shown by whichever paths resolve.
+type OutputSpec struct {
+	Format    string `yaml:"format"`     // plain (default), claude-stream-json or json
+	Text      string `yaml:"text"`       // assistant text
+	Tool      string `yaml:"tool"`       // tool name
+	ToolInput string `yaml:"tool_input"` // tool arguments object (file_path, command, ...)
+	Error     string `yaml:"error"`      // error message
+}
+
+// LoadFile reads a runner config file. A missing file yields an empty File.
+func LoadFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &File{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+	var f File
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	f.resolvePaths(filepath.Dir(path))
+	if f.Auto.RetryBackoff != "" {
+		if _, err := time.ParseDuration(f.Auto.RetryBackoff); err != nil {
+			return nil, fmt.Errorf("%s: auto.retry_backoff: %w", path, err)
+		}
+	}
+	for phase, spec := range f.PhaseAgents {
+		if _, err := ParsePhaseAgent(spec); err != nil {
+			return nil, fmt.Errorf("%s: phase_agents.%s: %w", path, phase, err)
+		}
+	}
+	for i, b := range f.Backends {
+		if err := b.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: backends[%d]: %w", path, i, err)
+		}
+	}
+	return &f, nil
+}
+
+// resolvePaths makes relative file paths in f relative to dir, the directory of
+// the config file. A bare agent_path (no separator) is left for PATH lookup.
+func (f *File) resolvePaths(dir string) {
+	if f.PrimeDirective != "" && !filepath.IsAbs(f.PrimeDirective) {
+		f.PrimeDirective = filepath.Join(dir, f.PrimeDirective)
+	}
+	if strings.ContainsRune(f.AgentPath, filepath.Separator) && !filepath.IsAbs(f.AgentPath) {
+		f.AgentPath = filepath.Join(dir, f.AgentPath)
+	}
+}
+
+// UserFilePath returns the path of the user-level config file, or "" if neither
+// $XDG_CONFIG_HOME nor the home directory is known.
+func UserFilePath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "bmad-runner", UserFileName)
+}
+
+// Validate checks that a backend spec is complete enough to run.
+func (b BackendSpec) Validate() error {
+	if b.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if b.Binary == "" {
+		return fmt.Errorf("backend %q: binary is required", b.Name)
+	}
+	if len(b.Args) == 0 {
+		return fmt.Errorf("backend %q: args is required", b.Name)
+	}
+	if !b.ArgsContain("{prompt}") && !b.ArgsContain("{prompt_file}") {
+		return fmt.Errorf("backend %q: args must pass the prompt with {prompt} or {prompt_file}", b.Name)
+	}
+	if b.ArgsContain("{model}") && b.Models["default"] == "" {
+		return fmt.Errorf("backend %q: models.default is required when args use {model}", b.Name)
+	}
+	switch b.Output.Format {
+	case "", OutputPlain, OutputClaudeStreamJSON:
+	case OutputJSON:
+		if b.Output.Text == "" && b.Output.Tool == "" && b.Output.Error == "" {
+			return fmt.Errorf("backend %q: json output needs at least one of text, tool or error paths", b.Name)
+		}
+	default:
+		return fmt.Errorf("backend %q: unknown output format %q (want %s, %s or %s)",
+			b.Name, b.Output.Format, OutputPlain, OutputClaudeStreamJSON, OutputJSON)
+	}
+	return nil
+}
+
+// ArgsContain reports whether any argument template uses placeholder.
+func (b BackendSpec) ArgsContain(placeholder string) bool {
+	for _, arg := range b.Args {
+		if strings.Contains(arg, placeholder) {
+			return true
+		}
+	}
+	return false
+}
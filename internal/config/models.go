@@ -1,47 +1,14 @@
 package config
 
-// DefaultModels maps each agent type to a map of workflow phase -> model name.
-// The special key "default" is used as a fallback for phases not explicitly listed.
+// DefaultModels maps each registered agent type to a map of workflow phase -> model
+// name. The special key "default" is used as a fallback for phases not explicitly
+// listed. The special key "fallback" names the model to switch to when a phase
+// keeps failing transiently (e.g. rate limits) on its usual model.
 //
-// Edit this map to change which model is used for each agent/phase combination.
-var DefaultModels = map[string]map[string]string{
-	AgentTypeCursorAgent: {
-		"create-story":    "claude-4.6-sonnet-medium",
-		"dev-story":       "composer-1.5",
-		"code-review":     "gemini-3-flash",
-		"retrospective":   "gemini-3-flash",
-		"correct-course":  "claude-4.6-sonnet-medium",
-		"sprint-planning": "claude-4.6-sonnet-medium",
-		"default":         "composer-1.5",
-	},
-	AgentTypeClaudeCode: {
-		"create-story":    "sonnet",
-		"dev-story":       "haiku",
-		"code-review":     "sonnet",
-		"retrospective":   "sonnet",
-		"correct-course":  "sonnet",
-		"sprint-planning": "sonnet",
-		"default":         "sonnet",
-	},
-	AgentTypeGeminiCLI: {
-		"create-story":    "gemini-3-pro",
-		"dev-story":       "gemini-3-flash",
-		"code-review":     "gemini-3-pro",
-		"retrospective":   "gemini-3-pro",
-		"correct-course":  "gemini-3-pro",
-		"sprint-planning": "gemini-3-pro",
-		"default":         "gemini-3-pro",
-	},
-	AgentTypeOpenCode: {
-		"create-story":    "opencode-go/kimi-k2.5",
-		"dev-story":       "opencode-go/minimax-m2.5",
-		"code-review":     "opencode-go/kimi-k2.5",
-		"retrospective":   "opencode-go/kimi-k2.5",
-		"correct-course":  "opencode-go/glm-5",
-		"sprint-planning": "opencode-go/glm-5",
-		"default":         "opencode-go/kimi-k2.5",
-	},
-}
+// The tables themselves live with each backend in internal/agent, which registers
+// them through RegisterAgent; config files' models: sections are merged on top
+// by OverrideModels.
+var DefaultModels = map[string]map[string]string{}
 
 // DefaultModel returns the default model name for the given agent type and workflow phase.
 // Falls back to the cursor-agent config if agentType is unrecognised, and to the
@@ -56,3 +23,19 @@ func DefaultModel(agentType, phase string) string {
 	}
 	return phases["default"]
 }
+
+// FallbackModel returns the model to fall back to when current keeps failing for the
+// given agent type: the agent's "fallback" entry, or its "default" entry if the
+// fallback is the model already in use. Returns "" if neither differs from current.
+func FallbackModel(agentType, current string) string {
+	phases, ok := DefaultModels[agentType]
+	if !ok {
+		phases = DefaultModels[AgentTypeCursorAgent]
+	}
+	for _, key := range []string{"fallback", "default"} {
+		if model := phases[key]; model != "" && model != current {
+			return model
+		}
+	}
+	return ""
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PhaseAgent is the agent backend, and optionally the model, that runs one phase
+// instead of the --agent-type backend.
+type PhaseAgent struct {
+	AgentType string
+	Model     string // empty: the agent's default model for the phase
+}
+
+// String formats p the way ParsePhaseAgent reads it.
+func (p PhaseAgent) String() string {
+	if p.Model == "" {
+		return p.AgentType
+	}
+	return p.AgentType + ":" + p.Model
+}
+
+// ParsePhaseAgent parses "agent" or "agent:model", e.g. "gemini-cli:gemini-3-pro".
+// Everything after the first colon is the model.
+func ParsePhaseAgent(s string) (PhaseAgent, error) {
+	agentType, model, _ := strings.Cut(strings.TrimSpace(s), ":")
+	p := PhaseAgent{AgentType: strings.TrimSpace(agentType), Model: strings.TrimSpace(model)}
+	if p.AgentType == "" {
+		return PhaseAgent{}, fmt.Errorf("invalid phase agent %q: missing agent type", s)
+	}
+	return p, nil
+}
+
+// ParsePhaseAgents parses per-phase agent specs such as "code-review=gemini-cli" or
+// "dev-story=claude-code:opus" into a phase -> agent map.
+func ParsePhaseAgents(specs []string) (map[string]PhaseAgent, error) {
+	agents := make(map[string]PhaseAgent, len(specs))
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		phase, value, ok := strings.Cut(spec, "=")
+		phase = strings.TrimSpace(phase)
+		if !ok || phase == "" {
+			return nil, fmt.Errorf("invalid phase agent %q: want phase=agent[:model]", spec)
+		}
+		p, err := ParsePhaseAgent(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid phase agent %q: missing agent type", spec)
+		}
+		agents[phase] = p
+	}
+	return agents, nil
+}
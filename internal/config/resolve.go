@@ -0,0 +1,214 @@
+package config
+
+import (
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// Source says where an effective setting came from.
+type Source string
+
+// Setting sources, from highest to lowest precedence.
+const (
+	SourceFlag    Source = "flag"
+	SourceEnv     Source = "env"
+	SourceProject Source = "project"
+	SourceUser    Source = "user"
+	SourceDefault Source = "default"
+)
+
+// Keys of the settings a config file can provide. They match the CLI flag names.
+const (
+	KeyAgentType      = "agent-type"
+	KeyAgentPath      = "agent-path"
+	KeyModel          = "model"
+	KeyPrimeDirective = "prime-directive"
+	KeyMaxIterations  = "max-iterations"
+	KeyMaxNewEpics    = "max-new-epics"
+	KeyMaxRetries     = "max-retries"
+	KeyRetryBackoff   = "retry-backoff"
+)
+
+// SettingKeys lists every key a config file can set, in display order.
+var SettingKeys = []string{
+	KeyAgentType, KeyAgentPath, KeyModel, KeyPrimeDirective,
+	KeyMaxIterations, KeyMaxNewEpics, KeyMaxRetries, KeyRetryBackoff,
+}
+
+// Layer is one config file in a Resolver.
+type Layer struct {
+	Source Source
+	Path   string
+	File   *File
+}
+
+// Resolver layers the project config file over the user config file. Flags and
+// environment variables sit above both and are resolved by the CLI.
+type Resolver struct {
+	layers []Layer // highest precedence first
+}
+
+// LoadResolver loads the project config file from projectRoot and the user config
+// file from UserFilePath. Missing files are treated as empty.
+func LoadResolver(projectRoot string) (*Resolver, error) {
+	r := &Resolver{}
+	projectPath := filepath.Join(projectRoot, FileName)
+	project, err := LoadFile(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	r.layers = append(r.layers, Layer{Source: SourceProject, Path: projectPath, File: project})
+	if userPath := UserFilePath(); userPath != "" {
+		user, err := LoadFile(userPath)
+		if err != nil {
+			return nil, err
+		}
+		r.layers = append(r.layers, Layer{Source: SourceUser, Path: userPath, File: user})
+	}
+	return r, nil
+}
+
+// Layers returns the resolver's config files, highest precedence first.
+func (r *Resolver) Layers() []Layer {
+	return r.layers
+}
+
+// Lookup returns the value of key from the highest-precedence file that sets it.
+func (r *Resolver) Lookup(key string) (string, Layer, bool) {
+	for _, l := range r.layers {
+		if v, ok := l.File.setting(key); ok {
+			return v, l, true
+		}
+	}
+	return "", Layer{}, false
+}
+
+// ModelOverride is one per-phase model set by a config file.
+type ModelOverride struct {
+	Model string
+	Layer Layer
+}
+
+// Models returns the per-phase model overrides for agentType, keyed by phase,
+// each from the highest-precedence file that sets it.
+func (r *Resolver) Models(agentType string) map[string]ModelOverride {
+	out := map[string]ModelOverride{}
+	for i := len(r.layers) - 1; i >= 0; i-- {
+		l := r.layers[i]
+		for phase, model := range l.File.Models[agentType] {
+			if model != "" {
+				out[phase] = ModelOverride{Model: model, Layer: l}
+			}
+		}
+	}
+	return out
+}
+
+// PhaseAgentOverride is one phase -> agent mapping set by a config file.
+type PhaseAgentOverride struct {
+	PhaseAgent
+	Layer Layer
+}
+
+// PhaseAgents returns the phase -> agent mappings from all files, keyed by phase,
+// each from the highest-precedence file that sets it.
+func (r *Resolver) PhaseAgents() map[string]PhaseAgentOverride {
+	out := map[string]PhaseAgentOverride{}
+	for i := len(r.layers) - 1; i >= 0; i-- {
+		l := r.layers[i]
+		for phase, spec := range l.File.PhaseAgents {
+			if p, err := ParsePhaseAgent(spec); err == nil {
+				out[phase] = PhaseAgentOverride{PhaseAgent: p, Layer: l}
+			}
+		}
+	}
+	return out
+}
+
+// ModelAgents returns the agent types any config file overrides models for, sorted.
+func (r *Resolver) ModelAgents() []string {
+	seen := map[string]bool{}
+	for _, l := range r.layers {
+		for agentType := range l.File.Models {
+			seen[agentType] = true
+		}
+	}
+	agents := make([]string, 0, len(seen))
+	for a := range seen {
+		agents = append(agents, a)
+	}
+	sort.Strings(agents)
+	return agents
+}
+
+// Backends returns the custom backends from all files; a project backend replaces
+// a user backend with the same name.
+func (r *Resolver) Backends() []BackendSpec {
+	var specs []BackendSpec
+	index := map[string]int{}
+	for i := len(r.layers) - 1; i >= 0; i-- {
+		for _, b := range r.layers[i].File.Backends {
+			if j, ok := index[b.Name]; ok {
+				specs[j] = b
+				continue
+			}
+			index[b.Name] = len(specs)
+			specs = append(specs, b)
+		}
+	}
+	return specs
+}
+
+// ApplyModels merges the resolver's per-phase model overrides into DefaultModels
+// for every agent type a file mentions.
+func (r *Resolver) ApplyModels() {
+	for _, agentType := range r.ModelAgents() {
+		OverrideModels(agentType, r.Models(agentType))
+	}
+}
+
+// OverrideModels sets per-phase models for agentType on top of its defaults.
+func OverrideModels(agentType string, overrides map[string]ModelOverride) {
+	if len(overrides) == 0 {
+		return
+	}
+	merged := map[string]string{}
+	for phase, model := range DefaultModels[agentType] {
+		merged[phase] = model
+	}
+	for phase, o := range overrides {
+		merged[phase] = o.Model
+	}
+	DefaultModels[agentType] = merged
+}
+
+// setting returns the file's value for key, if set.
+func (f *File) setting(key string) (string, bool) {
+	str := func(s string) (string, bool) { return s, s != "" }
+	num := func(n *int) (string, bool) {
+		if n == nil {
+			return "", false
+		}
+		return strconv.Itoa(*n), true
+	}
+	switch key {
+	case KeyAgentType:
+		return str(f.AgentType)
+	case KeyAgentPath:
+		return str(f.AgentPath)
+	case KeyModel:
+		return str(f.Model)
+	case KeyPrimeDirective:
+		return str(f.PrimeDirective)
+	case KeyMaxIterations:
+		return num(f.Auto.MaxIterations)
+	case KeyMaxNewEpics:
+		return num(f.Auto.MaxNewEpics)
+	case KeyMaxRetries:
+		return num(f.Auto.MaxRetries)
+	case KeyRetryBackoff:
+		return str(f.Auto.RetryBackoff)
+	}
+	return "", false
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ParsePhaseTimeouts parses per-phase timeout specs such as "dev-story=90m" or
+// "code-review: 20m" into a phase -> duration map. A bare duration ("2h") sets the
+// "default" entry, which applies to phases without their own limit.
+func ParsePhaseTimeouts(specs []string) (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration, len(specs))
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		phase, value := "default", spec
+		if i := strings.IndexAny(spec, "=:"); i >= 0 {
+			phase = strings.TrimSpace(spec[:i])
+			value = strings.TrimSpace(spec[i+1:])
+		}
+		if phase == "" {
+			return nil, fmt.Errorf("invalid phase timeout %q: missing phase name", spec)
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid phase timeout %q: %w", spec, err)
+		}
+		if d < 0 {
+			return nil, fmt.Errorf("invalid phase timeout %q: duration must not be negative", spec)
+		}
+		timeouts[phase] = d
+	}
+	return timeouts, nil
+}
+
+// MinIdleTimeout is the shortest idle timeout accepted: agents routinely go quiet
+// for a moment while they think.
+const MinIdleTimeout = time.Second
+
+// CheckIdleTimeout validates an --idle-timeout value; 0 disables the watchdog.
+func CheckIdleTimeout(d time.Duration) error {
+	switch {
+	case d < 0:
+		return fmt.Errorf("invalid idle timeout %s: duration must not be negative", d)
+	case d > 0 && d < MinIdleTimeout:
+		return fmt.Errorf("invalid idle timeout %s: must be 0 (disabled) or at least %s", d, MinIdleTimeout)
+	}
+	return nil
+}
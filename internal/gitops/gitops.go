@@ -0,0 +1,258 @@
+// Package gitops runs the git commands the runner needs to give each story its own
+// worktree and branch, bring the result back into the main working tree, and
+// snapshot the working tree so a failed story can be undone.
+package gitops
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Repo is a git repository, addressed through its main working tree.
+type Repo struct {
+	Root string // top-level directory of the main working tree
+}
+
+// Open returns the repository containing dir.
+func Open(dir string) (*Repo, error) {
+	root, err := Git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("%s is not in a git repository: %w", dir, err)
+	}
+	return &Repo{Root: root}, nil
+}
+
+// Git runs git with args in dir and returns its trimmed standard output. Errors
+// include git's standard error.
+func Git(dir string, args ...string) (string, error) {
+	return gitEnv(dir, nil, args...)
+}
+
+// gitEnv is Git with extra environment variables.
+func gitEnv(dir string, env []string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s: %w", strings.Join(args, " "), msg, err)
+		}
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// BranchExists reports whether the local branch name exists.
+func (r *Repo) BranchExists(name string) bool {
+	_, err := Git(r.Root, "rev-parse", "--verify", "--quiet", "refs/heads/"+name)
+	return err == nil
+}
+
+// WorktreeDir returns the directory for the runner's worktree called name. It is
+// inside the git directory, so it never shows up as untracked files.
+func (r *Repo) WorktreeDir(name string) (string, error) {
+	common, err := Git(r.Root, "rev-parse", "--git-common-dir")
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(common) {
+		common = filepath.Join(r.Root, common)
+	}
+	return filepath.Join(common, "bmad-worktrees", name), nil
+}
+
+// AddWorktree checks out branch in a new worktree at path, creating the branch
+// from HEAD if it does not exist. An existing worktree at path is reused as is.
+func (r *Repo) AddWorktree(path, branch string) error {
+	if top, err := Git(path, "rev-parse", "--show-toplevel"); err == nil && sameDir(top, path) {
+		return nil
+	}
+	if _, err := Git(r.Root, "worktree", "prune"); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating worktree directory: %w", err)
+	}
+	args := []string{"worktree", "add", path, branch}
+	if !r.BranchExists(branch) {
+		args = []string{"worktree", "add", "-b", branch, path, "HEAD"}
+	}
+	_, err := Git(r.Root, args...)
+	return err
+}
+
+// RemoveWorktree deletes the worktree at path, discarding uncommitted changes in it.
+func (r *Repo) RemoveWorktree(path string) error {
+	_, err := Git(r.Root, "worktree", "remove", "--force", path)
+	return err
+}
+
+// Changes returns the `git status --porcelain` lines for the working tree dir,
+// ignoring the paths in exclude (relative to dir). None means the tree is clean.
+func Changes(dir string, exclude ...string) ([]string, error) {
+	out, err := Git(dir, append([]string{"status", "--porcelain"}, pathspec(exclude)...)...)
+	if err != nil || out == "" {
+		return nil, err
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// CommitAll commits every change in the working tree dir, except the paths in
+// exclude (relative to dir), with message. It reports whether a commit was made.
+func CommitAll(dir, message string, exclude ...string) (bool, error) {
+	if _, err := Git(dir, append([]string{"add", "-A"}, pathspec(notIgnored(dir, exclude))...)...); err != nil {
+		return false, err
+	}
+	if _, err := Git(dir, "diff", "--cached", "--quiet"); err == nil {
+		return false, nil
+	}
+	if _, err := Git(dir, "commit", "-q", "--no-verify", "-m", message); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CommitPaths commits the changes to paths (relative to dir) in the working tree
+// dir, and nothing else, with message. It reports whether a commit was made.
+func CommitPaths(dir, message string, paths ...string) (bool, error) {
+	only := append([]string{"--"}, paths...)
+	if _, err := Git(dir, append([]string{"add"}, only...)...); err != nil {
+		return false, err
+	}
+	if _, err := Git(dir, append([]string{"diff", "--cached", "--quiet"}, only...)...); err == nil {
+		return false, nil
+	}
+	if _, err := Git(dir, append([]string{"commit", "-q", "--no-verify", "-m", message}, only...)...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ErrMergeConflict is returned by Merge when the branch cannot be merged cleanly.
+var ErrMergeConflict = errors.New("merge conflict")
+
+// Merge merges branch into the branch checked out in the main working tree,
+// fast-forwarding when possible. A merge that does not apply cleanly is aborted
+// and reported as ErrMergeConflict. It reports whether it fast-forwarded.
+func (r *Repo) Merge(branch string) (fastForward bool, err error) {
+	if _, err := Git(r.Root, "merge", "--ff-only", "-q", branch); err == nil {
+		return true, nil
+	}
+	if _, err := Git(r.Root, "merge", "--no-ff", "--no-edit", "-q", branch); err != nil {
+		Git(r.Root, "merge", "--abort")
+		return false, fmt.Errorf("merging %s: %w (%v)", branch, ErrMergeConflict, err)
+	}
+	return false, nil
+}
+
+// DeleteBranch deletes the local branch name, which must be merged.
+func (r *Repo) DeleteBranch(name string) error {
+	_, err := Git(r.Root, "branch", "-q", "-d", name)
+	return err
+}
+
+// Head returns the commit checked out in the main working tree.
+func (r *Repo) Head() (string, error) {
+	return Git(r.Root, "rev-parse", "HEAD")
+}
+
+// Snapshot records the main working tree, including untracked files but not the
+// paths in exclude, as a commit whose parent is HEAD, without touching the index,
+// the working tree or any branch. The commit is unreferenced until a branch is
+// created for it.
+func (r *Repo) Snapshot(message string, exclude ...string) (string, error) {
+	index, err := os.CreateTemp("", "bmad-index-")
+	if err != nil {
+		return "", fmt.Errorf("snapshot: %w", err)
+	}
+	index.Close()
+	defer os.Remove(index.Name())
+	env := []string{"GIT_INDEX_FILE=" + index.Name()}
+
+	if _, err := gitEnv(r.Root, env, "read-tree", "HEAD"); err != nil {
+		return "", err
+	}
+	if _, err := gitEnv(r.Root, env, append([]string{"add", "-A"}, pathspec(notIgnored(r.Root, exclude))...)...); err != nil {
+		return "", err
+	}
+	tree, err := gitEnv(r.Root, env, "write-tree")
+	if err != nil {
+		return "", err
+	}
+	return Git(r.Root, "commit-tree", tree, "-p", "HEAD", "-m", message)
+}
+
+// Restore puts the main working tree back to snapshot, a commit made by Snapshot
+// on top of head: the checked-out branch is reset to head, files created since
+// are deleted, and the snapshot's files are written back as uncommitted changes.
+// The paths in exclude and ignored files are left alone.
+func (r *Repo) Restore(head, snapshot string, exclude ...string) error {
+	if _, err := Git(r.Root, "reset", "-q", "--hard", head); err != nil {
+		return err
+	}
+	if _, err := Git(r.Root, append([]string{"clean", "-q", "-f", "-d"}, pathspec(exclude)...)...); err != nil {
+		return err
+	}
+	if _, err := Git(r.Root, "checkout", snapshot, "--", "."); err != nil {
+		return err
+	}
+	_, err := Git(r.Root, "reset", "-q")
+	return err
+}
+
+// CreateBranch creates the branch name at commit.
+func (r *Repo) CreateBranch(name, commit string) error {
+	_, err := Git(r.Root, "branch", name, commit)
+	return err
+}
+
+// RenameBranch renames the local branch from to to.
+func (r *Repo) RenameBranch(from, to string) error {
+	_, err := Git(r.Root, "branch", "-m", from, to)
+	return err
+}
+
+// DiscardBranch deletes the local branch name even if it is not merged.
+func (r *Repo) DiscardBranch(name string) error {
+	_, err := Git(r.Root, "branch", "-q", "-D", name)
+	return err
+}
+
+// notIgnored drops the paths in exclude (relative to dir) that git ignores. `git add`
+// refuses a pathspec naming an ignored path, even one that excludes it, and never
+// adds ignored files anyway.
+func notIgnored(dir string, exclude []string) []string {
+	var keep []string
+	for _, path := range exclude {
+		if _, err := Git(dir, "check-ignore", "-q", "--", path); err != nil {
+			keep = append(keep, path)
+		}
+	}
+	return keep
+}
+
+// pathspec returns the pathspec arguments selecting everything under the current
+// directory except exclude.
+func pathspec(exclude []string) []string {
+	args := []string{"--", "."}
+	for _, path := range exclude {
+		args = append(args, ":(exclude)"+filepath.ToSlash(path))
+	}
+	return args
+}
+
+// sameDir reports whether a and b name the same directory.
+func sameDir(a, b string) bool {
+	ai, err1 := os.Stat(a)
+	bi, err2 := os.Stat(b)
+	return err1 == nil && err2 == nil && os.SameFile(ai, bi)
+}
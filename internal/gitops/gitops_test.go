@@ -0,0 +1,238 @@
+package gitops
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// newRepo creates a repository with one commit, or skips the test without git.
+func newRepo(t *testing.T) *Repo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.name", "test"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "commit.gpgsign", "false"},
+	} {
+		if _, err := Git(dir, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(t, filepath.Join(dir, "a.txt"), "a\n")
+	if _, err := CommitAll(dir, "initial"); err != nil {
+		t.Fatal(err)
+	}
+	r, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	return r
+}
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWorktreeMerge(t *testing.T) {
+	t.Parallel()
+	r := newRepo(t)
+	wt, err := r.WorktreeDir("1-1-a")
+	if err != nil {
+		t.Fatalf("WorktreeDir: %v", err)
+	}
+	if err := r.AddWorktree(wt, "bmad/1-1-a"); err != nil {
+		t.Fatalf("AddWorktree: %v", err)
+	}
+	if err := r.AddWorktree(wt, "bmad/1-1-a"); err != nil {
+		t.Fatalf("AddWorktree (reuse): %v", err)
+	}
+	if !r.BranchExists("bmad/1-1-a") {
+		t.Fatal("branch bmad/1-1-a not created")
+	}
+	if out, _ := Git(r.Root, "status", "--porcelain"); out != "" {
+		t.Errorf("main tree status = %q, want clean (worktree must not show up)", out)
+	}
+
+	writeFile(t, filepath.Join(wt, "b.txt"), "b\n")
+	writeFile(t, filepath.Join(wt, "status.yaml"), "excluded\n")
+	if changes, err := Changes(wt, "status.yaml"); err != nil || len(changes) != 1 || changes[0] != "?? b.txt" {
+		t.Errorf("Changes = %q, %v; want [?? b.txt]", changes, err)
+	}
+	committed, err := CommitAll(wt, "story 1-1-a", "status.yaml")
+	if err != nil || !committed {
+		t.Fatalf("CommitAll = %v, %v; want a commit", committed, err)
+	}
+	if committed, err := CommitAll(wt, "nothing", "status.yaml"); err != nil || committed {
+		t.Errorf("CommitAll with no changes = %v, %v; want no commit", committed, err)
+	}
+	if changes, err := Changes(wt, "status.yaml"); err != nil || changes != nil {
+		t.Errorf("Changes after commit = %q, %v; want none", changes, err)
+	}
+
+	ff, err := r.Merge("bmad/1-1-a")
+	if err != nil || !ff {
+		t.Fatalf("Merge = %v, %v; want a fast-forward", ff, err)
+	}
+	if _, err := os.Stat(filepath.Join(r.Root, "b.txt")); err != nil {
+		t.Errorf("b.txt not merged: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(r.Root, "status.yaml")); err == nil {
+		t.Error("excluded status.yaml was committed")
+	}
+
+	if err := r.RemoveWorktree(wt); err != nil {
+		t.Fatalf("RemoveWorktree: %v", err)
+	}
+	if err := r.DeleteBranch("bmad/1-1-a"); err != nil {
+		t.Fatalf("DeleteBranch: %v", err)
+	}
+	if r.BranchExists("bmad/1-1-a") {
+		t.Error("branch still exists after DeleteBranch")
+	}
+}
+
+func TestMergeConflict(t *testing.T) {
+	t.Parallel()
+	r := newRepo(t)
+	wt, err := r.WorktreeDir("1-2-b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AddWorktree(wt, "bmad/1-2-b"); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(wt, "a.txt"), "from the story\n")
+	if _, err := CommitAll(wt, "story"); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(r.Root, "a.txt"), "from main\n")
+	if _, err := CommitAll(r.Root, "main"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.Merge("bmad/1-2-b"); !errors.Is(err, ErrMergeConflict) {
+		t.Fatalf("Merge = %v, want ErrMergeConflict", err)
+	}
+	if out, _ := Git(r.Root, "status", "--porcelain"); out != "" {
+		t.Errorf("main tree status after aborted merge = %q, want clean", out)
+	}
+
+	if err := r.RemoveWorktree(wt); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.DeleteBranch("bmad/1-2-b"); err == nil {
+		t.Error("DeleteBranch of an unmerged branch succeeded")
+	}
+	if err := r.DiscardBranch("bmad/1-2-b"); err != nil || r.BranchExists("bmad/1-2-b") {
+		t.Errorf("DiscardBranch = %v, want the branch deleted", err)
+	}
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	t.Parallel()
+	r := newRepo(t)
+	head, err := r.Head()
+	if err != nil {
+		t.Fatalf("Head: %v", err)
+	}
+	writeFile(t, filepath.Join(r.Root, "a.txt"), "edited before the story\n")
+	writeFile(t, filepath.Join(r.Root, "u.txt"), "untracked before the story\n")
+	if err := os.Mkdir(filepath.Join(r.Root, "logs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := Changes(r.Root, "logs")
+
+	snapshot, err := r.Snapshot("before 1-1-a", "logs")
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	if after, _ := Changes(r.Root, "logs"); !slices.Equal(before, after) {
+		t.Errorf("Changes after Snapshot = %q, want %q", after, before)
+	}
+
+	// The story commits one file, edits and deletes others and writes a log.
+	writeFile(t, filepath.Join(r.Root, "b.txt"), "b\n")
+	if _, err := CommitAll(r.Root, "phase", "logs"); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(r.Root, "a.txt"), "edited by the story\n")
+	writeFile(t, filepath.Join(r.Root, "c.txt"), "c\n")
+	writeFile(t, filepath.Join(r.Root, "logs", "run.log"), "log\n")
+	if err := os.Remove(filepath.Join(r.Root, "u.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	parked, err := r.Snapshot("failed 1-1-a", "logs")
+	if err != nil {
+		t.Fatalf("Snapshot of the failed story: %v", err)
+	}
+	if err := r.CreateBranch("bmad-failed/1-1-a", parked); err != nil {
+		t.Fatalf("CreateBranch: %v", err)
+	}
+	if out, err := Git(r.Root, "show", "bmad-failed/1-1-a:c.txt"); err != nil || out != "c" {
+		t.Errorf("parked c.txt = %q, %v; want c", out, err)
+	}
+
+	if err := r.Restore(head, snapshot, "logs"); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if got, _ := r.Head(); got != head {
+		t.Errorf("HEAD after Restore = %s, want %s", got, head)
+	}
+	for name, want := range map[string]string{
+		"a.txt":        "edited before the story\n",
+		"u.txt":        "untracked before the story\n",
+		"logs/run.log": "log\n",
+		"b.txt":        "",
+		"c.txt":        "",
+	} {
+		data, err := os.ReadFile(filepath.Join(r.Root, name))
+		if want == "" {
+			if err == nil {
+				t.Errorf("%s still exists after Restore", name)
+			}
+			continue
+		}
+		if string(data) != want {
+			t.Errorf("%s after Restore = %q, %v; want %q", name, data, err, want)
+		}
+	}
+	if after, _ := Changes(r.Root, "logs"); !slices.Equal(before, after) {
+		t.Errorf("Changes after Restore = %q, want %q", after, before)
+	}
+}
+
+// Excluding the runner's files works when they are gitignored too.
+func TestCommitAllIgnoredExclude(t *testing.T) {
+	t.Parallel()
+	r := newRepo(t)
+	writeFile(t, filepath.Join(r.Root, ".gitignore"), "logs/\nsession.json\n")
+	if err := os.Mkdir(filepath.Join(r.Root, "logs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(r.Root, "logs", "run.log"), "log\n")
+	writeFile(t, filepath.Join(r.Root, "session.json"), "{}\n")
+	writeFile(t, filepath.Join(r.Root, "b.txt"), "b\n")
+
+	committed, err := CommitAll(r.Root, "phase", "logs", "session.json")
+	if err != nil || !committed {
+		t.Fatalf("CommitAll = %v, %v; want a commit", committed, err)
+	}
+	if changes, err := Changes(r.Root); err != nil || changes != nil {
+		t.Errorf("Changes after commit = %q, %v; want none", changes, err)
+	}
+	if _, err := r.Snapshot("snapshot", "logs", "session.json"); err != nil {
+		t.Errorf("Snapshot: %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+// Package session keeps the checkpoint journal of a `run auto` session, so an
+// interrupted session can resume from the phase it stopped in.
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/MBFrosty/BMAD-Runner/internal/status"
+)
+
+// DefaultPath is where the journal is kept, relative to the project root.
+const DefaultPath = "_bmad-output/runner-session.json"
+
+// Journal is the checkpoint of a `run auto` session: the story in progress with
+// the phases already done, and the loop counters the session caps apply to.
+type Journal struct {
+	StatusFile string    `json:"status_file"`
+	Started    time.Time `json:"started"`
+	Updated    time.Time `json:"updated"`
+
+	// Iteration is the auto-loop iteration in progress (0-based).
+	Iteration         int    `json:"iteration"`
+	EpicPlanningCount int    `json:"epic_planning_count"`
+	LastStalledStory  string `json:"last_stalled_story,omitempty"`
+	StallCount        int    `json:"stall_count,omitempty"`
+
+	// Work is the story in progress, or nil between stories.
+	Work *Work `json:"work,omitempty"`
+	// Stories are the stories in progress at once with --parallel.
+	Stories []*Work `json:"stories,omitempty"`
+}
+
+// Work is a story the auto loop is running phases for.
+type Work struct {
+	Epic      string   `json:"epic"`
+	Story     string   `json:"story"`
+	Phases    []string `json:"phases"`    // phases planned, in order
+	Completed []string `json:"completed"` // phases that finished successfully
+}
+
+// New returns the journal of a session started now against statusFile.
+func New(statusFile string) *Journal {
+	now := time.Now()
+	return &Journal{StatusFile: statusFile, Started: now, Updated: now}
+}
+
+// Load reads the journal at path. It returns nil and no error if there is none.
+func Load(path string) (*Journal, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading session journal: %w", err)
+	}
+	var j Journal
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &j, nil
+}
+
+// Save writes the journal to path atomically, so a crash mid-write leaves the
+// previous checkpoint intact.
+func (j *Journal) Save(path string) error {
+	j.Updated = time.Now()
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding session journal: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating session journal directory: %w", err)
+	}
+	return status.WriteFileAtomic(path, append(data, '\n'))
+}
+
+// Remove deletes the journal at path, if any.
+func Remove(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("removing session journal: %w", err)
+	}
+	return nil
+}
+
+// Interrupted returns the story j stopped in the middle of, or nil if it has no
+// phases left. j may be nil.
+func (j *Journal) Interrupted() *Work {
+	if j == nil || j.Work == nil || len(j.Work.Remaining()) == 0 {
+		return nil
+	}
+	return j.Work
+}
+
+// InterruptedStories returns every story j stopped in the middle of: the story
+// in progress, then the --parallel ones. j may be nil.
+func (j *Journal) InterruptedStories() []*Work {
+	var out []*Work
+	if w := j.Interrupted(); w != nil {
+		out = append(out, w)
+	}
+	if j != nil {
+		for _, w := range j.Stories {
+			if len(w.Remaining()) > 0 {
+				out = append(out, w)
+			}
+		}
+	}
+	return out
+}
+
+// StartWork records that the phases of story are about to run.
+func (j *Journal) StartWork(epic, story string, phases []string) {
+	j.Work = &Work{Epic: epic, Story: story, Phases: phases, Completed: []string{}}
+}
+
+// CompletePhase records that phase of the story in progress finished.
+func (j *Journal) CompletePhase(phase string) {
+	if j.Work != nil {
+		j.Work.Completed = append(j.Work.Completed, phase)
+	}
+}
+
+// Remaining returns the planned phases of w that have not completed, in order.
+func (w *Work) Remaining() []string {
+	var phases []string
+	for _, p := range w.Phases {
+		if !slices.Contains(w.Completed, p) {
+			phases = append(phases, p)
+		}
+	}
+	return phases
+}
+
+// StartStory records that the phases of story are about to run alongside other
+// stories (--parallel), replacing an earlier entry for it.
+func (j *Journal) StartStory(epic, story string, phases []string) {
+	j.EndStory(story)
+	j.Stories = append(j.Stories, &Work{Epic: epic, Story: story, Phases: phases, Completed: []string{}})
+}
+
+// CompleteStoryPhase records that phase of the --parallel story finished.
+func (j *Journal) CompleteStoryPhase(story, phase string) {
+	for _, w := range j.Stories {
+		if w.Story == story {
+			w.Completed = append(w.Completed, phase)
+		}
+	}
+}
+
+// EndStory drops the --parallel story from the stories in progress.
+func (j *Journal) EndStory(story string) {
+	j.Stories = slices.DeleteFunc(j.Stories, func(w *Work) bool { return w.Story == story })
+}
@@ -0,0 +1,117 @@
+package session
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestJournalRoundTrip(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "_bmad-output", "runner-session.json")
+
+	if j, err := Load(path); err != nil || j != nil {
+		t.Fatalf("Load(missing) = %v, %v; want nil, nil", j, err)
+	}
+
+	j := New("/p/sprint-status.yaml")
+	j.Iteration, j.EpicPlanningCount = 4, 2
+	j.LastStalledStory, j.StallCount = "1-2-b", 1
+	j.StartWork("epic-1", "1-3-c", []string{"create-story", "dev-story", "code-review"})
+	j.CompletePhase("create-story")
+	if err := j.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got.StatusFile != j.StatusFile || got.Iteration != 4 || got.EpicPlanningCount != 2 || got.LastStalledStory != "1-2-b" || got.StallCount != 1 {
+		t.Errorf("Load() = %+v, want %+v", got, j)
+	}
+	if got.Work == nil || got.Work.Story != "1-3-c" || got.Work.Epic != "epic-1" {
+		t.Fatalf("Load().Work = %+v, want story 1-3-c of epic-1", got.Work)
+	}
+	if want := []string{"dev-story", "code-review"}; !reflect.DeepEqual(got.Work.Remaining(), want) {
+		t.Errorf("Remaining() = %v, want %v", got.Work.Remaining(), want)
+	}
+	if got.Interrupted() != got.Work {
+		t.Errorf("Interrupted() = %+v, want the work in progress", got.Interrupted())
+	}
+	got.CompletePhase("dev-story")
+	got.CompletePhase("code-review")
+	if w := got.Interrupted(); w != nil {
+		t.Errorf("Interrupted() with all phases done = %+v, want nil", w)
+	}
+	if w := (*Journal)(nil).Interrupted(); w != nil {
+		t.Errorf("nil Interrupted() = %+v, want nil", w)
+	}
+
+	if err := Remove(path); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if err := Remove(path); err != nil {
+		t.Errorf("Remove(missing) = %v, want nil", err)
+	}
+	if j, _ := Load(path); j != nil {
+		t.Errorf("Load after Remove = %+v, want nil", j)
+	}
+}
+
+func TestJournalStories(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "runner-session.json")
+	j := New("/p/sprint-status.yaml")
+	j.StartStory("epic-1", "1-1-a", []string{"create-story", "dev-story", "code-review"})
+	j.StartStory("epic-1", "1-2-b", []string{"dev-story", "code-review"})
+	j.StartStory("epic-1", "1-3-c", []string{"code-review"})
+	j.CompleteStoryPhase("1-1-a", "create-story")
+	j.CompleteStoryPhase("1-3-c", "code-review")
+	j.EndStory("1-2-b")
+	if err := j.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	stories := got.InterruptedStories()
+	if len(stories) != 1 || stories[0].Story != "1-1-a" {
+		t.Fatalf("InterruptedStories() = %+v, want 1-1-a only", stories)
+	}
+	if want := []string{"dev-story", "code-review"}; !reflect.DeepEqual(stories[0].Remaining(), want) {
+		t.Errorf("Remaining() = %v, want %v", stories[0].Remaining(), want)
+	}
+
+	// Restarting a story replaces its entry.
+	got.StartStory("epic-1", "1-1-a", []string{"create-story"})
+	if len(got.Stories) != 2 || !reflect.DeepEqual(got.InterruptedStories()[0].Remaining(), []string{"create-story"}) {
+		t.Errorf("after restart Stories = %+v", got.Stories)
+	}
+
+	got.StartWork("epic-2", "2-1-d", []string{"dev-story"})
+	if stories := got.InterruptedStories(); len(stories) != 2 || stories[0].Story != "2-1-d" {
+		t.Errorf("InterruptedStories() = %+v, want 2-1-d first", stories)
+	}
+	if stories := (*Journal)(nil).InterruptedStories(); stories != nil {
+		t.Errorf("nil InterruptedStories() = %+v, want nil", stories)
+	}
+}
+
+func TestWorkRemaining(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		phases, completed, want []string
+	}{
+		{[]string{"create-story", "dev-story", "code-review"}, nil, []string{"create-story", "dev-story", "code-review"}},
+		{[]string{"dev-story", "code-review"}, []string{"dev-story"}, []string{"code-review"}},
+		{[]string{"code-review"}, []string{"code-review"}, nil},
+	}
+	for _, tt := range tests {
+		w := &Work{Phases: tt.phases, Completed: tt.completed}
+		if got := w.Remaining(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Remaining(%v done of %v) = %v, want %v", tt.completed, tt.phases, got, tt.want)
+		}
+	}
+}
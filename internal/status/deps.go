@@ -0,0 +1,61 @@
+package status
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Dependencies are the story keys a story depends on. In YAML it is a list of
+// keys or, for a single dependency, just the key.
+type Dependencies []string
+
+// UnmarshalYAML accepts a single key as well as a list of keys.
+func (d *Dependencies) UnmarshalYAML(n *yaml.Node) error {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		if n.Tag == "!!null" || n.Value == "" {
+			*d = nil
+			return nil
+		}
+		*d = Dependencies{n.Value}
+		return nil
+	case yaml.SequenceNode:
+		var keys []string
+		if err := n.Decode(&keys); err != nil {
+			return err
+		}
+		*d = keys
+		return nil
+	}
+	return fmt.Errorf("line %d: depends_on entries must be a story key or a list of story keys", n.Line)
+}
+
+// Blockers returns the stories key depends on that are not finished yet.
+// Dependencies without a development_status entry are ignored.
+func (s *SprintStatus) Blockers(key string) []string {
+	var blockers []string
+	for _, dep := range s.DependsOn[key] {
+		if st, ok := s.DevStatus[dep]; ok && !st.Finished() {
+			blockers = append(blockers, dep)
+		}
+	}
+	return blockers
+}
+
+// ReadyStories returns the pending stories of epicKey, in document order, that
+// have no Blockers and so can run now.
+func (s *SprintStatus) ReadyStories(epicKey string) []string {
+	var ready []string
+	for _, g := range s.EpicGroups() {
+		if g.EpicKey != epicKey {
+			continue
+		}
+		for _, st := range g.Stories {
+			if !st.Value.Finished() && len(s.Blockers(st.Key)) == 0 {
+				ready = append(ready, st.Key)
+			}
+		}
+	}
+	return ready
+}
@@ -0,0 +1,57 @@
+package status
+
+import (
+	"slices"
+	"testing"
+)
+
+const dependsOnFixture = `development_status:
+  epic-1: in-progress
+  1-1-schema: done
+  1-2-api: in-progress
+  1-3-ui: backlog
+  1-4-docs: backlog
+  1-5-search: backlog
+  1-6-export: deferred
+  epic-1-retrospective: optional
+  epic-2: backlog
+  2-1-auth: backlog
+depends_on:
+  1-2-api: 1-1-schema
+  1-3-ui: [1-2-api, 1-1-schema]
+  1-4-docs: [1-6-export, 9-9-gone]
+  1-5-search: [2-1-auth]
+`
+
+func TestReadyStories(t *testing.T) {
+	t.Parallel()
+	s, err := ParseBytes([]byte(dependsOnFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string][]string{
+		"1-1-schema": nil,
+		"1-2-api":    nil,
+		"1-3-ui":     {"1-2-api"},
+		"1-4-docs":   nil, // deferred and unknown dependencies do not block
+		"1-5-search": {"2-1-auth"},
+	}
+	for key, want := range tests {
+		if got := s.Blockers(key); !slices.Equal(got, want) {
+			t.Errorf("Blockers(%q) = %v, want %v", key, got, want)
+		}
+	}
+	if got, want := s.ReadyStories("epic-1"), []string{"1-2-api", "1-4-docs"}; !slices.Equal(got, want) {
+		t.Errorf("ReadyStories(epic-1) = %v, want %v", got, want)
+	}
+	if got := s.ReadyStories("epic-3"); got != nil {
+		t.Errorf("ReadyStories(epic-3) = %v, want none", got)
+	}
+}
+
+func TestDependenciesInvalid(t *testing.T) {
+	t.Parallel()
+	if _, err := ParseBytes([]byte("development_status:\n  epic-1: backlog\ndepends_on:\n  1-1-a: {b: c}\n")); err == nil {
+		t.Error("ParseBytes accepted a mapping as depends_on entry")
+	}
+}
@@ -10,15 +10,15 @@ import (
 
 // OrderedEntry is a single development_status key-value pair in document order.
 type OrderedEntry struct {
-	Key   string
-	Value string
+	Key   string `json:"key" yaml:"key"`
+	Value State  `json:"status" yaml:"status"`
 }
 
 // EpicGroup holds an epic key, its stories, and its retrospective entry in order.
 type EpicGroup struct {
 	EpicKey       string
 	RetroKey      string
-	RetroStatus   string
+	RetroStatus   State
 	Stories       []OrderedEntry
 }
 
@@ -26,9 +26,13 @@ type EpicGroup struct {
 type SprintStatus struct {
 	Generated     string            `yaml:"generated"`
 	Project       string            `yaml:"project"`
-	DevStatus     map[string]string `yaml:"development_status"`
+	DevStatus     map[string]State  `yaml:"development_status"`
 	StoryLocation string            `yaml:"story_location"`
 
+	// DependsOn maps a story key to the stories that must be finished before it
+	// starts (optional; see ReadyStories).
+	DependsOn map[string]Dependencies `yaml:"depends_on"`
+
 	// OrderedEntries preserves development_status key order for epic grouping.
 	OrderedEntries []OrderedEntry
 }
@@ -72,6 +76,24 @@ func ParseBytes(data []byte) (*SprintStatus, error) {
 }
 
 func extractOrderedEntries(doc *yaml.Node) ([]OrderedEntry, error) {
+	valNode, err := devStatusNode(doc)
+	if err != nil {
+		return nil, err
+	}
+	var entries []OrderedEntry
+	for j := 0; j < len(valNode.Content)-1; j += 2 {
+		k := valNode.Content[j]
+		v := valNode.Content[j+1]
+		if k.Kind != yaml.ScalarNode || v.Kind != yaml.ScalarNode {
+			continue
+		}
+		entries = append(entries, OrderedEntry{Key: k.Value, Value: State(v.Value)})
+	}
+	return entries, nil
+}
+
+// rootMapping returns the top-level mapping node of a parsed sprint-status document.
+func rootMapping(doc *yaml.Node) (*yaml.Node, error) {
 	root := doc
 	if len(doc.Content) > 0 {
 		root = doc.Content[0]
@@ -79,29 +101,33 @@ func extractOrderedEntries(doc *yaml.Node) ([]OrderedEntry, error) {
 	if root.Kind != yaml.MappingNode {
 		return nil, fmt.Errorf("expected root mapping node")
 	}
+	return root, nil
+}
 
-	// Find development_status key
-	for i := 0; i < len(root.Content)-1; i += 2 {
-		keyNode := root.Content[i]
-		valNode := root.Content[i+1]
-		if keyNode.Kind != yaml.ScalarNode || keyNode.Value != "development_status" {
-			continue
-		}
-		if valNode.Kind != yaml.MappingNode {
-			return nil, fmt.Errorf("development_status must be a mapping")
+// mappingValue returns the value node for key in mapping node m, or nil.
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i < len(m.Content)-1; i += 2 {
+		if k := m.Content[i]; k.Kind == yaml.ScalarNode && k.Value == key {
+			return m.Content[i+1]
 		}
-		var entries []OrderedEntry
-		for j := 0; j < len(valNode.Content)-1; j += 2 {
-			k := valNode.Content[j]
-			v := valNode.Content[j+1]
-			if k.Kind != yaml.ScalarNode || v.Kind != yaml.ScalarNode {
-				continue
-			}
-			entries = append(entries, OrderedEntry{Key: k.Value, Value: v.Value})
-		}
-		return entries, nil
 	}
-	return nil, fmt.Errorf("development_status key not found")
+	return nil
+}
+
+// devStatusNode returns the development_status mapping node of a parsed document.
+func devStatusNode(doc *yaml.Node) (*yaml.Node, error) {
+	root, err := rootMapping(doc)
+	if err != nil {
+		return nil, err
+	}
+	valNode := mappingValue(root, "development_status")
+	if valNode == nil {
+		return nil, fmt.Errorf("development_status key not found")
+	}
+	if valNode.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("development_status must be a mapping")
+	}
+	return valNode, nil
 }
 
 // EpicGroups returns development_status entries grouped by epic in document order.
@@ -126,16 +152,6 @@ func (s *SprintStatus) EpicGroups() []EpicGroup {
 	return groups
 }
 
-// storyStarted returns true if the story status indicates work has begun (beyond backlog/drafted).
-func storyStarted(status string) bool {
-	switch status {
-	case "backlog", "drafted":
-		return false
-	default:
-		return true
-	}
-}
-
 // NextWork returns the next action to take: "story", "retrospective", or "" if all done.
 // epicKey is the epic identifier for display.
 // storyKey is the first pending story key when action is "story"; empty for retrospective.
@@ -148,7 +164,7 @@ func (s *SprintStatus) NextWork() (action, epicKey, storyKey string, found bool)
 		allStoriesDone := true
 		var firstPendingStory string
 		for _, st := range g.Stories {
-			if st.Value != "done" && st.Value != "deferred" {
+			if !st.Value.Finished() {
 				allStoriesDone = false
 				if firstPendingStory == "" {
 					firstPendingStory = st.Key
@@ -157,14 +173,13 @@ func (s *SprintStatus) NextWork() (action, epicKey, storyKey string, found bool)
 		}
 
 		if allStoriesDone && g.RetroKey != "" {
-			retroComplete := g.RetroStatus == "done" || g.RetroStatus == "completed"
-			if !retroComplete {
+			if !g.RetroStatus.Finished() {
 				// "optional" = run only when no story has started on the next epic; skip if we've moved on
-				if g.RetroStatus == "optional" {
+				if g.RetroStatus == StateOptional {
 					nextEpicStarted := false
 					if i+1 < len(groups) {
 						for _, st := range groups[i+1].Stories {
-							if storyStarted(st.Value) {
+							if st.Value.Started() {
 								nextEpicStarted = true
 								break
 							}
@@ -206,7 +221,7 @@ func (s *SprintStatus) EpicProgress(epicKey string) (done, total int) {
 		}
 		total = len(g.Stories)
 		for _, st := range g.Stories {
-			if st.Value == "done" || st.Value == "deferred" {
+			if st.Value.Finished() {
 				done++
 			}
 		}
@@ -275,6 +275,22 @@ func TestNextWork(t *testing.T) {
 			wantStoryKey: "2-1-x",
 			wantFound:    true,
 		},
+		{
+			name:         "optional retro next epic ready-for-dev counts as not started",
+			dev:          "  epic-1: \"\"\n  1-1-a: done\n  epic-1-retrospective: optional\n  epic-2: \"\"\n  2-1-x: ready-for-dev",
+			wantAction:   "retrospective",
+			wantEpicKey:  "epic-1",
+			wantStoryKey: "",
+			wantFound:    true,
+		},
+		{
+			name:         "completed retro is finished",
+			dev:          "  epic-1: \"\"\n  1-1-a: done\n  epic-1-retrospective: completed\n  epic-2: \"\"\n  2-1-x: review",
+			wantAction:   "story",
+			wantEpicKey:  "epic-2",
+			wantStoryKey: "2-1-x",
+			wantFound:    true,
+		},
 		{
 			name:         "deferred stories count as done",
 			dev:          "  epic-1: \"\"\n  1-1-a: deferred\n  epic-1-retrospective: pending",
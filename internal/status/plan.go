@@ -0,0 +1,59 @@
+package status
+
+// PlanStep is one iteration of a simulated auto loop: a story and the phases it
+// still needs, or a retrospective.
+type PlanStep struct {
+	Action string   // "story" or "retrospective"
+	Epic   string   // epic key
+	Key    string   // story key, or the retrospective key
+	From   State    // the entry's status when the step starts
+	Phases []string // phases run, in order
+}
+
+// Plan simulates the auto loop over a copy of s, assuming every phase succeeds:
+// each iteration takes NextWork, runs the phases the story's state still needs
+// (see RemainingPhases) and marks it done, or marks the retrospective done. It
+// returns the steps in order; s is not modified.
+func (s *SprintStatus) Plan() []PlanStep {
+	sim := &SprintStatus{
+		DevStatus:      make(map[string]State, len(s.DevStatus)),
+		OrderedEntries: append([]OrderedEntry(nil), s.OrderedEntries...),
+	}
+	for k, v := range s.DevStatus {
+		sim.DevStatus[k] = v
+	}
+
+	var steps []PlanStep
+	// Every step finishes one entry, so the loop ends after at most one step per entry.
+	for i := 0; i <= len(sim.OrderedEntries); i++ {
+		action, epicKey, storyKey, found := sim.NextWork()
+		if !found {
+			break
+		}
+		step := PlanStep{Action: action, Epic: epicKey, Key: storyKey}
+		if action == "retrospective" {
+			for _, g := range sim.EpicGroups() {
+				if g.EpicKey == epicKey {
+					step.Key, step.From = g.RetroKey, g.RetroStatus
+				}
+			}
+			step.Phases = []string{"retrospective"}
+		} else {
+			step.From = sim.DevStatus[storyKey]
+			step.Phases = step.From.RemainingPhases()
+		}
+		steps = append(steps, step)
+		sim.set(step.Key, StateDone)
+	}
+	return steps
+}
+
+// set changes the status of key in memory.
+func (s *SprintStatus) set(key string, value State) {
+	s.DevStatus[key] = value
+	for i := range s.OrderedEntries {
+		if s.OrderedEntries[i].Key == key {
+			s.OrderedEntries[i].Value = value
+		}
+	}
+}
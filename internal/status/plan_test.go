@@ -0,0 +1,72 @@
+package status
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPlan(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		yaml string
+		want []PlanStep
+	}{
+		{
+			name: "resumes stories and runs retrospectives between epics",
+			yaml: `development_status:
+  epic-1: in-progress
+  1-1-a: done
+  1-2-b: in-review
+  1-3-c: drafted
+  epic-1-retrospective: optional
+  epic-2: backlog
+  2-1-a: backlog
+  2-2-b: ready-for-dev
+  epic-2-retrospective: optional
+`,
+			want: []PlanStep{
+				{"story", "epic-1", "1-2-b", StateInReview, []string{"code-review"}},
+				{"story", "epic-1", "1-3-c", StateDrafted, []string{"dev-story", "code-review"}},
+				{"retrospective", "epic-1", "epic-1-retrospective", StateOptional, []string{"retrospective"}},
+				{"story", "epic-2", "2-1-a", StateBacklog, []string{"create-story", "dev-story", "code-review"}},
+				{"story", "epic-2", "2-2-b", StateReadyForDev, []string{"dev-story", "code-review"}},
+				{"retrospective", "epic-2", "epic-2-retrospective", StateOptional, []string{"retrospective"}},
+			},
+		},
+		{
+			name: "optional retrospective skipped once the next epic has started",
+			yaml: `development_status:
+  epic-1: done
+  1-1-a: done
+  epic-1-retrospective: optional
+  epic-2: in-progress
+  2-1-a: in-progress
+`,
+			want: []PlanStep{
+				{"story", "epic-2", "2-1-a", StateInProgress, []string{"dev-story", "code-review"}},
+			},
+		},
+		{
+			name: "nothing pending",
+			yaml: "development_status:\n  epic-1: done\n  1-1-a: done\n  epic-1-retrospective: done\n",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s, err := ParseBytes([]byte(tt.yaml))
+			if err != nil {
+				t.Fatalf("ParseBytes: %v", err)
+			}
+			before := s.Summary()
+			if got := s.Plan(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Plan() =\n%v\nwant\n%v", got, tt.want)
+			}
+			if !reflect.DeepEqual(s.Summary(), before) {
+				t.Error("Plan() modified the sprint status")
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package status
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// State is a development_status value: the BMAD workflow state of a story, epic
+// or retrospective.
+type State string
+
+// BMAD states. Some have a synonym used by other BMAD versions: ready-for-dev for
+// drafted and review for in-review.
+const (
+	StateBacklog     State = "backlog"
+	StateDrafted     State = "drafted"
+	StateReadyForDev State = "ready-for-dev"
+	StateInProgress  State = "in-progress"
+	StateInReview    State = "in-review"
+	StateReview      State = "review"
+	StateDone        State = "done"
+	StateDeferred    State = "deferred" // story dropped from the sprint; counts as finished
+
+	StateContexted State = "contexted" // epic with its technical context prepared
+
+	StateOptional  State = "optional"  // retrospective not yet held
+	StateCompleted State = "completed" // retrospective held (older BMAD versions)
+)
+
+// Kind is the kind of a development_status key.
+type Kind string
+
+// Kinds of development_status keys.
+const (
+	KindEpic          Kind = "epic"
+	KindStory         Kind = "story"
+	KindRetrospective Kind = "retrospective"
+)
+
+// KeyKind returns the kind of a development_status key, or "" if it matches none.
+func KeyKind(key string) Kind {
+	switch {
+	case retrospectiveRe.MatchString(key):
+		return KindRetrospective
+	case epicRe.MatchString(key):
+		return KindEpic
+	case storyRe.MatchString(key):
+		return KindStory
+	}
+	return ""
+}
+
+// storyFlow is the order a story moves through the pipeline; states at the same
+// index are synonyms.
+var storyFlow = [][]State{
+	{StateBacklog},
+	{StateDrafted, StateReadyForDev},
+	{StateInProgress},
+	{StateInReview, StateReview},
+	{StateDone},
+}
+
+// transitions lists, per kind and state, the states a key ordinarily moves to
+// next, including deferring a story and reopening finished work.
+var transitions = map[Kind]map[State][]State{
+	KindStory: {
+		StateBacklog:     {StateDrafted, StateReadyForDev, StateDeferred},
+		StateDrafted:     {StateInProgress, StateBacklog, StateDeferred},
+		StateReadyForDev: {StateInProgress, StateBacklog, StateDeferred},
+		StateInProgress:  {StateInReview, StateReview, StateBacklog, StateDeferred},
+		StateInReview:    {StateDone, StateInProgress, StateBacklog, StateDeferred},
+		StateReview:      {StateDone, StateInProgress, StateBacklog, StateDeferred},
+		StateDone:        {StateInProgress, StateBacklog},
+		StateDeferred:    {StateBacklog, StateInProgress},
+	},
+	KindEpic: {
+		StateBacklog:    {StateContexted, StateInProgress},
+		StateContexted:  {StateInProgress, StateBacklog},
+		StateInProgress: {StateDone, StateBacklog},
+		StateDone:       {StateInProgress, StateBacklog},
+	},
+	KindRetrospective: {
+		StateOptional:  {StateDone, StateCompleted},
+		StateDone:      {StateOptional},
+		StateCompleted: {StateOptional},
+	},
+}
+
+// States returns the states a key of the given kind can have.
+func States(kind Kind) []State {
+	var states []State
+	for s := range transitions[kind] {
+		states = append(states, s)
+	}
+	slices.SortFunc(states, func(a, b State) int { return strings.Compare(string(a), string(b)) })
+	return states
+}
+
+// Valid reports whether s is a state for keys of kind.
+func (s State) Valid(kind Kind) bool {
+	_, ok := transitions[kind][s]
+	return ok
+}
+
+// Finished reports whether no more work is pending for a key in state s: done,
+// deferred, or (for retrospectives) completed.
+func (s State) Finished() bool {
+	switch s {
+	case StateDone, StateDeferred, StateCompleted:
+		return true
+	}
+	return false
+}
+
+// Started reports whether work on a story in state s has begun, i.e. it has moved
+// past being drafted. Unknown states count as started.
+func (s State) Started() bool {
+	switch s {
+	case StateBacklog, StateDrafted, StateReadyForDev:
+		return false
+	}
+	return true
+}
+
+// RemainingPhases returns the workflow phases a story in state s still has to go
+// through, in order. Unknown states run the whole pipeline.
+func (s State) RemainingPhases() []string {
+	switch s {
+	case StateDone, StateDeferred:
+		return nil
+	case StateInReview, StateReview:
+		return []string{"code-review"}
+	case StateDrafted, StateReadyForDev, StateInProgress:
+		return []string{"dev-story", "code-review"}
+	default:
+		return []string{"create-story", "dev-story", "code-review"}
+	}
+}
+
+// CanTransition reports whether a key of kind ordinarily moves from one state to
+// the other (see transitions).
+func CanTransition(kind Kind, from, to State) bool {
+	return slices.Contains(transitions[kind][from], to)
+}
+
+// storyStep returns the position of s in storyFlow, or -1.
+func storyStep(s State) int {
+	for i, states := range storyFlow {
+		if slices.Contains(states, s) {
+			return i
+		}
+	}
+	return -1
+}
+
+// TransitionWarning describes what is unusual about moving a key of kind from one
+// state to another, or returns "" for a legal transition (see CanTransition).
+// Skipping story states means the workflows for the skipped phases never run.
+func TransitionWarning(kind Kind, from, to State) string {
+	if from == to || CanTransition(kind, from, to) || !from.Valid(kind) || !to.Valid(kind) {
+		return ""
+	}
+	if kind == KindStory {
+		fromStep, toStep := storyStep(from), storyStep(to)
+		if fromStep >= 0 && toStep > fromStep+1 {
+			var skipped []string
+			for _, states := range storyFlow[fromStep+1 : toStep] {
+				skipped = append(skipped, string(states[0]))
+			}
+			return fmt.Sprintf("%s → %s skips %s", from, to, strings.Join(skipped, ", "))
+		}
+	}
+	return fmt.Sprintf("%s → %s is not a usual %s transition", from, to, kind)
+}
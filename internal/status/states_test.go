@@ -0,0 +1,129 @@
+package status
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestKeyKind(t *testing.T) {
+	t.Parallel()
+	tests := map[string]Kind{
+		"epic-1":               KindEpic,
+		"epic-12":              KindEpic,
+		"1-2-add-login":        KindStory,
+		"epic-3-retrospective": KindRetrospective,
+		"project":              "",
+		"epic-one":             "",
+	}
+	for key, want := range tests {
+		if got := KeyKind(key); got != want {
+			t.Errorf("KeyKind(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestStateValid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		kind  Kind
+		state State
+		want  bool
+	}{
+		{KindStory, StateReadyForDev, true},
+		{KindStory, StateReview, true},
+		{KindStory, StateOptional, false},
+		{KindEpic, StateContexted, true},
+		{KindEpic, StateDeferred, false},
+		{KindRetrospective, StateCompleted, true},
+		{"", StateDone, false},
+	}
+	for _, tt := range tests {
+		if got := tt.state.Valid(tt.kind); got != tt.want {
+			t.Errorf("%q.Valid(%q) = %v, want %v", tt.state, tt.kind, got, tt.want)
+		}
+	}
+}
+
+func TestRemainingPhases(t *testing.T) {
+	t.Parallel()
+	full := []string{"create-story", "dev-story", "code-review"}
+	tests := map[State][]string{
+		StateBacklog:     full,
+		"":               full,
+		"something-else": full,
+		StateDrafted:     {"dev-story", "code-review"},
+		StateReadyForDev: {"dev-story", "code-review"},
+		StateInProgress:  {"dev-story", "code-review"},
+		StateInReview:    {"code-review"},
+		StateReview:      {"code-review"},
+		StateDone:        nil,
+		StateDeferred:    nil,
+	}
+	for state, want := range tests {
+		if got := state.RemainingPhases(); !slices.Equal(got, want) {
+			t.Errorf("%q.RemainingPhases() = %v, want %v", state, got, want)
+		}
+	}
+}
+
+func TestStateFinishedAndStarted(t *testing.T) {
+	t.Parallel()
+	for _, s := range []State{StateDone, StateDeferred, StateCompleted} {
+		if !s.Finished() {
+			t.Errorf("%q.Finished() = false, want true", s)
+		}
+	}
+	for _, s := range []State{StateBacklog, StateReadyForDev, StateInReview, StateOptional} {
+		if s.Finished() {
+			t.Errorf("%q.Finished() = true, want false", s)
+		}
+	}
+	for _, s := range []State{StateBacklog, StateDrafted, StateReadyForDev} {
+		if s.Started() {
+			t.Errorf("%q.Started() = true, want false", s)
+		}
+	}
+	for _, s := range []State{StateInProgress, StateReview, StateDone} {
+		if !s.Started() {
+			t.Errorf("%q.Started() = false, want true", s)
+		}
+	}
+}
+
+func TestTransitionWarning(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		kind     Kind
+		from, to State
+		want     string // substring; "" means a legal transition
+	}{
+		{"next step", KindStory, StateBacklog, StateDrafted, ""},
+		{"synonym step", KindStory, StateReadyForDev, StateInProgress, ""},
+		{"review to done", KindStory, StateReview, StateDone, ""},
+		{"reopen", KindStory, StateDone, StateInProgress, ""},
+		{"defer", KindStory, StateInProgress, StateDeferred, ""},
+		{"backlog to done", KindStory, StateBacklog, StateDone, "skips drafted, in-progress, in-review"},
+		{"drafted to in-review", KindStory, StateDrafted, StateInReview, "skips in-progress"},
+		{"done to in-review", KindStory, StateDone, StateInReview, "not a usual story transition"},
+		{"epic backlog to done", KindEpic, StateBacklog, StateDone, "not a usual epic transition"},
+		{"epic in-progress to done", KindEpic, StateInProgress, StateDone, ""},
+		{"unknown state", KindStory, StateBacklog, "finished", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := TransitionWarning(tt.kind, tt.from, tt.to)
+			if tt.want == "" {
+				if got != "" {
+					t.Errorf("TransitionWarning(%q, %q, %q) = %q, want none", tt.kind, tt.from, tt.to, got)
+				}
+				return
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("TransitionWarning(%q, %q, %q) = %q, want containing %q", tt.kind, tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package status
+
+// Summary is a machine-readable view of a sprint-status file: epics in document
+// order with their stories, retrospective and progress, plus the next work item.
+type Summary struct {
+	Project   string        `json:"project" yaml:"project"`
+	Generated string        `json:"generated" yaml:"generated"`
+	Next      *NextItem     `json:"next" yaml:"next"` // nil when all work is done
+	Epics     []EpicSummary `json:"epics" yaml:"epics"`
+
+	// Ungrouped holds entries that belong to no epic: stories and retrospectives
+	// before the first epic, and keys that are not epics, stories or retrospectives.
+	Ungrouped []OrderedEntry `json:"ungrouped,omitempty" yaml:"ungrouped,omitempty"`
+}
+
+// NextItem is the work item NextWork picks.
+type NextItem struct {
+	Action string `json:"action" yaml:"action"` // "story" or "retrospective"
+	Epic   string `json:"epic" yaml:"epic"`
+	Key    string `json:"key" yaml:"key"` // story or retrospective key
+}
+
+// EpicSummary is one epic with its entries and story progress.
+type EpicSummary struct {
+	Key           string         `json:"key" yaml:"key"`
+	Status        State          `json:"status" yaml:"status"`
+	Done          int            `json:"done" yaml:"done"` // stories done or deferred
+	Total         int            `json:"total" yaml:"total"`
+	Stories       []OrderedEntry `json:"stories" yaml:"stories"`
+	Retrospective *OrderedEntry  `json:"retrospective,omitempty" yaml:"retrospective,omitempty"`
+}
+
+// Summary builds the Summary of s.
+func (s *SprintStatus) Summary() Summary {
+	sum := Summary{Project: s.Project, Generated: s.Generated, Epics: []EpicSummary{}}
+	grouped := map[string]bool{}
+	for _, g := range s.EpicGroups() {
+		e := EpicSummary{Key: g.EpicKey, Status: s.DevStatus[g.EpicKey], Stories: []OrderedEntry{}}
+		grouped[g.EpicKey] = true
+		for _, st := range g.Stories {
+			e.Stories = append(e.Stories, st)
+			e.Total++
+			if st.Value.Finished() {
+				e.Done++
+			}
+			grouped[st.Key] = true
+		}
+		if g.RetroKey != "" {
+			e.Retrospective = &OrderedEntry{Key: g.RetroKey, Value: g.RetroStatus}
+			grouped[g.RetroKey] = true
+		}
+		sum.Epics = append(sum.Epics, e)
+	}
+	for _, e := range s.OrderedEntries {
+		if !grouped[e.Key] {
+			sum.Ungrouped = append(sum.Ungrouped, e)
+		}
+	}
+
+	if action, epicKey, storyKey, found := s.NextWork(); found {
+		next := &NextItem{Action: action, Epic: epicKey, Key: storyKey}
+		if action == "retrospective" {
+			for _, e := range sum.Epics {
+				if e.Key == epicKey && e.Retrospective != nil {
+					next.Key = e.Retrospective.Key
+				}
+			}
+		}
+		sum.Next = next
+	}
+	return sum
+}
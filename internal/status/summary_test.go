@@ -0,0 +1,51 @@
+package status
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSummary(t *testing.T) {
+	t.Parallel()
+	s, err := ParseBytes([]byte(`project: demo
+generated: 2025-01-01
+development_status:
+  0-1-stray: backlog
+  epic-1: done
+  1-1-a: done
+  1-2-b: deferred
+  epic-1-retrospective: optional
+  epic-2: in-progress
+  2-1-a: backlog
+  notes: keep
+`))
+	if err != nil {
+		t.Fatalf("ParseBytes: %v", err)
+	}
+	want := Summary{
+		Project:   "demo",
+		Generated: "2025-01-01",
+		Next:      &NextItem{Action: "retrospective", Epic: "epic-1", Key: "epic-1-retrospective"},
+		Epics: []EpicSummary{
+			{
+				Key: "epic-1", Status: StateDone, Done: 2, Total: 2,
+				Stories:       []OrderedEntry{{"1-1-a", StateDone}, {"1-2-b", StateDeferred}},
+				Retrospective: &OrderedEntry{"epic-1-retrospective", StateOptional},
+			},
+			{
+				Key: "epic-2", Status: StateInProgress, Done: 0, Total: 1,
+				Stories: []OrderedEntry{{"2-1-a", StateBacklog}},
+			},
+		},
+		Ungrouped: []OrderedEntry{{"0-1-stray", StateBacklog}, {"notes", "keep"}},
+	}
+	if got := s.Summary(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Summary() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	s.DevStatus["epic-1-retrospective"] = StateDone
+	s.OrderedEntries[4].Value = StateDone
+	if got := s.Summary().Next; !reflect.DeepEqual(got, &NextItem{Action: "story", Epic: "epic-2", Key: "2-1-a"}) {
+		t.Errorf("Summary().Next = %+v, want story 2-1-a", got)
+	}
+}
@@ -0,0 +1,223 @@
+package status
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Issue severities. Errors make the file unreliable for the runner; warnings are
+// unusual but workable.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Issue is one problem Validate found in a sprint-status file.
+type Issue struct {
+	Line     int // 1-based; 0 if the problem is not tied to a line
+	Key      string
+	Severity string
+	Message  string
+}
+
+func (i Issue) String() string {
+	var b strings.Builder
+	if i.Line > 0 {
+		fmt.Fprintf(&b, "line %d: ", i.Line)
+	}
+	if i.Key != "" {
+		fmt.Fprintf(&b, "%s: ", i.Key)
+	}
+	b.WriteString(i.Message)
+	return b.String()
+}
+
+// ValidateFile reads and validates the sprint-status file at path.
+func ValidateFile(path string) ([]Issue, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading sprint-status file: %w", err)
+	}
+	return Validate(data), nil
+}
+
+// Validate checks sprint-status YAML for problems the parser tolerates or hides:
+// malformed development_status entries, duplicate keys, unknown states, stories
+// outside an epic or under the wrong epic, epics without a retrospective entry,
+// epics marked done while stories are pending, and depends_on entries naming
+// unknown keys. Issues are in document order, followed by depends_on issues.
+func Validate(data []byte) []Issue {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return []Issue{{Severity: SeverityError, Message: err.Error()}}
+	}
+	if len(doc.Content) == 0 {
+		return []Issue{{Severity: SeverityError, Message: "file is empty"}}
+	}
+	devStatus, err := devStatusNode(&doc)
+	if err != nil {
+		return []Issue{{Line: doc.Content[0].Line, Severity: SeverityError, Message: err.Error()}}
+	}
+
+	v := &validator{seen: map[string]int{}}
+	for i := 0; i+1 < len(devStatus.Content); i += 2 {
+		v.entry(devStatus.Content[i], devStatus.Content[i+1])
+	}
+	v.closeEpic()
+	if root, err := rootMapping(&doc); err == nil {
+		if deps := mappingValue(root, "depends_on"); deps != nil {
+			v.dependsOn(deps)
+		}
+	}
+	return v.issues
+}
+
+// validator walks development_status entries in order, tracking the enclosing epic.
+type validator struct {
+	issues []Issue
+	seen   map[string]int // key -> line of first occurrence
+
+	epic        *yaml.Node // key node of the enclosing epic, nil before the first
+	epicState   State
+	epicNum     string
+	hasRetro    bool
+	pendingKeys []string // stories of the enclosing epic that are not finished
+}
+
+func (v *validator) add(line int, key, severity, format string, args ...interface{}) {
+	v.issues = append(v.issues, Issue{Line: line, Key: key, Severity: severity, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) entry(k, val *yaml.Node) {
+	key := k.Value
+	if k.Kind != yaml.ScalarNode {
+		v.add(k.Line, "", SeverityError, "key must be a scalar")
+		return
+	}
+	if first, ok := v.seen[key]; ok {
+		v.add(k.Line, key, SeverityError, "duplicate key (first defined on line %d)", first)
+		return
+	}
+	v.seen[key] = k.Line
+
+	if val.Kind != yaml.ScalarNode {
+		v.add(val.Line, key, SeverityError, "status must be a single value, not a %s", nodeKindName(val.Kind))
+		return
+	}
+	state := State(val.Value)
+	kind := KeyKind(key)
+	switch {
+	case kind == "":
+		v.add(k.Line, key, SeverityWarning, "not an epic, story or retrospective key; the runner ignores it")
+	case val.Tag == "!!null" || state == "":
+		// Epics are often left empty ("epic-1: \"\"") by older BMAD versions.
+		if kind != KindEpic {
+			v.add(val.Line, key, SeverityError, "missing status")
+		}
+	case !state.Valid(kind):
+		v.add(val.Line, key, SeverityError, "unknown %s status %q (known: %s)", kind, state, joinStates(States(kind)))
+	}
+
+	switch kind {
+	case KindEpic:
+		v.closeEpic()
+		v.epic, v.epicState, v.hasRetro, v.pendingKeys = k, state, false, nil
+		v.epicNum = strings.TrimPrefix(key, "epic-")
+	case KindStory:
+		if v.epic == nil {
+			v.add(k.Line, key, SeverityError, "story appears before any epic; the runner never picks it up")
+			return
+		}
+		if num, _, _ := strings.Cut(key, "-"); num != v.epicNum {
+			v.add(k.Line, key, SeverityError, "story of epic %s is listed under %s", num, v.epic.Value)
+		}
+		if !state.Finished() {
+			v.pendingKeys = append(v.pendingKeys, key)
+		}
+	case KindRetrospective:
+		if v.epic == nil {
+			v.add(k.Line, key, SeverityError, "retrospective appears before any epic")
+			return
+		}
+		if want := v.epic.Value + "-retrospective"; key != want {
+			v.add(k.Line, key, SeverityError, "retrospective is listed under %s (expected %s)", v.epic.Value, want)
+		}
+		v.hasRetro = true
+	}
+}
+
+// closeEpic reports problems with the enclosing epic once all its entries are seen.
+func (v *validator) closeEpic() {
+	if v.epic == nil {
+		return
+	}
+	key := v.epic.Value
+	if !v.hasRetro {
+		v.add(v.epic.Line, key, SeverityWarning, "no %s-retrospective entry; the runner will not run a retrospective for it", key)
+	}
+	if v.epicState == StateDone && len(v.pendingKeys) > 0 {
+		v.add(v.epic.Line, key, SeverityError, "marked done but has pending stories: %s", strings.Join(v.pendingKeys, ", "))
+	}
+}
+
+// dependsOn checks the depends_on mapping against the development_status keys.
+// Unknown keys are only warnings: the runner ignores those dependencies.
+func (v *validator) dependsOn(deps *yaml.Node) {
+	if deps.Kind != yaml.MappingNode {
+		v.add(deps.Line, "depends_on", SeverityError, "must map story keys to the stories they depend on, not a %s", nodeKindName(deps.Kind))
+		return
+	}
+	for i := 0; i+1 < len(deps.Content); i += 2 {
+		k, val := deps.Content[i], deps.Content[i+1]
+		key := k.Value
+		if _, ok := v.seen[key]; !ok {
+			v.add(k.Line, key, SeverityWarning, "depends_on entry for a key with no development_status entry")
+		}
+		var refs []*yaml.Node
+		switch val.Kind {
+		case yaml.ScalarNode:
+			if val.Tag == "!!null" || val.Value == "" {
+				continue
+			}
+			refs = []*yaml.Node{val}
+		case yaml.SequenceNode:
+			refs = val.Content
+		default:
+			v.add(val.Line, key, SeverityError, "depends_on must be a story key or a list of story keys, not a %s", nodeKindName(val.Kind))
+			continue
+		}
+		for _, ref := range refs {
+			switch _, ok := v.seen[ref.Value]; {
+			case ref.Kind != yaml.ScalarNode:
+				v.add(ref.Line, key, SeverityError, "depends_on must be a story key or a list of story keys, not a %s", nodeKindName(ref.Kind))
+			case ref.Value == key:
+				v.add(ref.Line, key, SeverityError, "depends on itself")
+			case !ok:
+				v.add(ref.Line, key, SeverityWarning, "depends on %s, which has no development_status entry; the dependency is ignored", ref.Value)
+			}
+		}
+	}
+}
+
+func nodeKindName(k yaml.Kind) string {
+	switch k {
+	case yaml.MappingNode:
+		return "mapping"
+	case yaml.SequenceNode:
+		return "list"
+	case yaml.AliasNode:
+		return "alias"
+	}
+	return "document"
+}
+
+func joinStates(states []State) string {
+	s := make([]string, len(states))
+	for i, st := range states {
+		s[i] = string(st)
+	}
+	return strings.Join(s, ", ")
+}
@@ -0,0 +1,114 @@
+package status
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+	type want struct {
+		line     int
+		key      string
+		severity string
+		message  string // substring
+	}
+	tests := []struct {
+		name string
+		yaml string
+		want []want
+	}{
+		{
+			name: "valid file",
+			yaml: sprintStatusFixture + "  epic-2-retrospective: optional\n",
+			want: nil,
+		},
+		{
+			name: "unknown status",
+			yaml: "development_status:\n  epic-1: in-progress\n  1-1-a: finished\n  epic-1-retrospective: optional\n",
+			want: []want{{3, "1-1-a", SeverityError, `unknown story status "finished"`}},
+		},
+		{
+			name: "story before any epic",
+			yaml: "development_status:\n  1-1-a: backlog\n  epic-1: backlog\n  epic-1-retrospective: optional\n",
+			want: []want{{2, "1-1-a", SeverityError, "before any epic"}},
+		},
+		{
+			name: "story under the wrong epic",
+			yaml: "development_status:\n  epic-1: backlog\n  2-1-a: backlog\n  epic-1-retrospective: optional\n",
+			want: []want{{3, "2-1-a", SeverityError, "story of epic 2 is listed under epic-1"}},
+		},
+		{
+			name: "duplicate key",
+			yaml: "development_status:\n  epic-1: backlog\n  1-1-a: backlog\n  1-1-a: done\n  epic-1-retrospective: optional\n",
+			want: []want{{4, "1-1-a", SeverityError, "duplicate key (first defined on line 3)"}},
+		},
+		{
+			name: "missing retrospective",
+			yaml: "development_status:\n  epic-1: backlog\n  1-1-a: backlog\n  epic-2: backlog\n  2-1-a: backlog\n  epic-2-retrospective: optional\n",
+			want: []want{{2, "epic-1", SeverityWarning, "no epic-1-retrospective entry"}},
+		},
+		{
+			name: "retrospective under the wrong epic",
+			yaml: "development_status:\n  epic-1: backlog\n  epic-2-retrospective: optional\n",
+			want: []want{
+				{3, "epic-2-retrospective", SeverityError, "listed under epic-1"},
+			},
+		},
+		{
+			name: "epic done with pending stories",
+			yaml: "development_status:\n  epic-1: done\n  1-1-a: done\n  1-2-b: in-progress\n  1-3-c: deferred\n  epic-1-retrospective: optional\n",
+			want: []want{{2, "epic-1", SeverityError, "marked done but has pending stories: 1-2-b"}},
+		},
+		{
+			name: "non-scalar and empty entries",
+			yaml: "development_status:\n  epic-1: \"\"\n  1-1-a: [backlog]\n  1-2-b:\n  notes: hello\n  epic-1-retrospective: optional\n",
+			want: []want{
+				{3, "1-1-a", SeverityError, "not a list"},
+				{4, "1-2-b", SeverityError, "missing status"},
+				{5, "notes", SeverityWarning, "not an epic, story or retrospective key"},
+			},
+		},
+		{
+			name: "depends_on",
+			yaml: "development_status:\n  epic-1: backlog\n  1-1-a: backlog\n  1-2-b: backlog\n  epic-1-retrospective: optional\ndepends_on:\n  1-2-b: [1-1-a, 1-9-z]\n  1-1-a: 1-1-a\n  1-3-c: 1-1-a\n  1-2-x: {a: b}\n",
+			want: []want{
+				{7, "1-2-b", SeverityWarning, "depends on 1-9-z, which has no development_status entry"},
+				{8, "1-1-a", SeverityError, "depends on itself"},
+				{9, "1-3-c", SeverityWarning, "no development_status entry"},
+				{10, "1-2-x", SeverityWarning, "no development_status entry"},
+				{10, "1-2-x", SeverityError, "not a mapping"},
+			},
+		},
+		{
+			name: "depends_on not a mapping",
+			yaml: "development_status:\n  epic-1: backlog\n  epic-1-retrospective: optional\ndepends_on: [1-1-a]\n",
+			want: []want{{4, "depends_on", SeverityError, "not a list"}},
+		},
+		{
+			name: "missing development_status",
+			yaml: "generated: 2025-01-01\nproject: p\n",
+			want: []want{{1, "", SeverityError, "development_status key not found"}},
+		},
+		{
+			name: "invalid yaml",
+			yaml: "development_status: [\n",
+			want: []want{{0, "", SeverityError, "yaml"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := Validate([]byte(tt.yaml))
+			if len(got) != len(tt.want) {
+				t.Fatalf("Validate() = %v, want %d issues", got, len(tt.want))
+			}
+			for i, w := range tt.want {
+				g := got[i]
+				if g.Line != w.line || g.Key != w.key || g.Severity != w.severity || !strings.Contains(g.Message, w.message) {
+					t.Errorf("issue %d = %+v, want line %d, key %q, %s containing %q", i, g, w.line, w.key, w.severity, w.message)
+				}
+			}
+		})
+	}
+}
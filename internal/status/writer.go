@@ -0,0 +1,266 @@
+package status
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SetStatus sets development_status[key] to value in the sprint-status file at
+// path and bumps its generated timestamp. Only those two scalars are rewritten;
+// comments, key order and formatting are left as they are. The file is replaced
+// atomically, so readers (and a crash mid-write) never see a partial file.
+// Setting an entry to the value it already has leaves the file untouched.
+func SetStatus(path, key string, value State) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading sprint-status file: %w", err)
+	}
+	updated, err := UpdateStatus(data, key, value, time.Now())
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(updated, data) {
+		return nil
+	}
+	return WriteFileAtomic(path, updated)
+}
+
+// UpdateStatus returns data with development_status[key] set to value and the
+// generated timestamp set to now (in the layout the file already uses). The key
+// must already exist. data is returned unchanged if the entry already has value.
+func UpdateStatus(data []byte, key string, value State, now time.Time) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("unmarshaling yaml node: %w", err)
+	}
+	devStatus, err := devStatusNode(&doc)
+	if err != nil {
+		return nil, err
+	}
+	entry := mappingValue(devStatus, key)
+	if entry == nil {
+		return nil, fmt.Errorf("development_status has no entry %q", key)
+	}
+	if entry.Kind != yaml.ScalarNode {
+		return nil, fmt.Errorf("development_status entry %q is not a scalar", key)
+	}
+	if State(entry.Value) == value {
+		return data, nil
+	}
+
+	edits := []scalarEdit{{node: entry, value: string(value), flow: devStatus.Style&yaml.FlowStyle != 0}}
+	root, _ := rootMapping(&doc)
+	if gen := mappingValue(root, "generated"); gen != nil && gen.Kind == yaml.ScalarNode {
+		edits = append(edits, scalarEdit{node: gen, value: formatGenerated(gen.Value, now)})
+	}
+	out, err := applyScalarEdits(data, edits)
+	if err != nil {
+		return nil, fmt.Errorf("updating %s: %w", key, err)
+	}
+
+	// The edit is positional, so make sure it changed exactly what it meant to.
+	check, err := ParseBytes(out)
+	if err != nil {
+		return nil, fmt.Errorf("updating %s: result does not parse: %w", key, err)
+	}
+	before, err := ParseBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	if check.DevStatus[key] != value || len(check.OrderedEntries) != len(before.OrderedEntries) {
+		return nil, fmt.Errorf("updating %s: could not rewrite the entry in place", key)
+	}
+	return out, nil
+}
+
+// WriteFileAtomic replaces path with data by writing a temporary file in the same
+// directory and renaming it over path. The file keeps path's permissions.
+func WriteFileAtomic(path string, data []byte) error {
+	perm := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// generatedLayouts are the timestamp layouts recognised in the generated field.
+var generatedLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"}
+
+// formatGenerated formats now in the layout of the existing generated value, or
+// as a date if that value is in a layout it does not recognise.
+func formatGenerated(old string, now time.Time) string {
+	for _, layout := range generatedLayouts {
+		if _, err := time.Parse(layout, old); err == nil {
+			return now.Format(layout)
+		}
+	}
+	return now.Format("2006-01-02")
+}
+
+// scalarEdit replaces the source text of a scalar node with a new value.
+type scalarEdit struct {
+	node  *yaml.Node
+	value string
+	flow  bool // node sits in a flow collection, where , ] and } end a plain scalar
+}
+
+// applyScalarEdits rewrites each edit's scalar in data, keeping its quoting style.
+func applyScalarEdits(data []byte, edits []scalarEdit) ([]byte, error) {
+	type span struct {
+		start, end int
+		text       string
+	}
+	var spans []span
+	for _, e := range edits {
+		start, end, err := scalarSpan(data, e.node, e.flow)
+		if err != nil {
+			return nil, err
+		}
+		text := encodeScalar(e.value, e.node)
+		if start == end && start > 0 && data[start-1] == ':' {
+			text = " " + text // empty value ("key:"), positioned right after the colon
+		}
+		spans = append(spans, span{start, end, text})
+	}
+	// Apply from the end of the file so earlier offsets stay valid.
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start > spans[j].start })
+	out := append([]byte(nil), data...)
+	for _, s := range spans {
+		out = append(out[:s.start], append([]byte(s.text), out[s.end:]...)...)
+	}
+	return out, nil
+}
+
+// scalarSpan returns the byte range of node's source text in data.
+func scalarSpan(data []byte, node *yaml.Node, flow bool) (int, int, error) {
+	start, err := offsetOf(data, node.Line, node.Column)
+	if err != nil {
+		return 0, 0, err
+	}
+	lineEnd := bytes.IndexByte(data[start:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(data)
+	} else {
+		lineEnd += start
+	}
+	line := data[start:lineEnd]
+
+	switch {
+	case node.Style&yaml.DoubleQuotedStyle != 0:
+		for i := 1; i < len(line); i++ {
+			switch line[i] {
+			case '\\':
+				i++
+			case '"':
+				return start, start + i + 1, nil
+			}
+		}
+	case node.Style&yaml.SingleQuotedStyle != 0:
+		for i := 1; i < len(line); i++ {
+			if line[i] == '\'' {
+				if i+1 < len(line) && line[i+1] == '\'' {
+					i++
+					continue
+				}
+				return start, start + i + 1, nil
+			}
+		}
+	case node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0:
+		return 0, 0, fmt.Errorf("line %d: block scalars are not supported", node.Line)
+	default:
+		end := len(line)
+		if i := bytes.Index(line, []byte(" #")); i >= 0 {
+			end = i
+		}
+		if flow {
+			if i := bytes.IndexAny(line[:end], ",]}"); i >= 0 {
+				end = i
+			}
+		}
+		text := bytes.TrimRight(line[:end], " \t\r")
+		if string(text) != node.Value {
+			return 0, 0, fmt.Errorf("line %d: unexpected text %q for value %q", node.Line, text, node.Value)
+		}
+		return start, start + len(text), nil
+	}
+	return 0, 0, fmt.Errorf("line %d: unterminated quoted value", node.Line)
+}
+
+// offsetOf converts a 1-based line and (character) column to a byte offset.
+func offsetOf(data []byte, line, column int) (int, error) {
+	off := 0
+	for l := 1; l < line; l++ {
+		i := bytes.IndexByte(data[off:], '\n')
+		if i < 0 {
+			return 0, fmt.Errorf("line %d is past the end of the file", line)
+		}
+		off += i + 1
+	}
+	for c := 1; c < column; c++ {
+		if off >= len(data) || data[off] == '\n' {
+			return 0, fmt.Errorf("column %d is past the end of line %d", column, line)
+		}
+		_, size := utf8.DecodeRune(data[off:])
+		off += size
+	}
+	return off, nil
+}
+
+// plainScalarRe matches values that can be written unquoted without changing type.
+var plainScalarRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]*$`)
+
+// encodeScalar renders value to replace the scalar old. Quoted values keep their
+// quotes; a plain value stays plain only if it resolves to the same YAML type as
+// old did (a status string stays a string, a generated date stays a date).
+func encodeScalar(value string, old *yaml.Node) string {
+	switch {
+	case old.Style&yaml.SingleQuotedStyle != 0:
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	case old.Style&yaml.DoubleQuotedStyle != 0:
+		return strconv.Quote(value)
+	}
+	want := old.ShortTag()
+	if want == "!!null" {
+		want = "!!str" // an empty entry is being filled in
+	}
+	if plainScalarRe.MatchString(value) {
+		var n yaml.Node
+		if yaml.Unmarshal([]byte(value), &n) == nil && len(n.Content) == 1 && n.Content[0].ShortTag() == want {
+			return value
+		}
+	}
+	return strconv.Quote(value)
+}
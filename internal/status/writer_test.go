@@ -0,0 +1,220 @@
+package status
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sprintStatusFixture = `# generated: 2025-01-01
+# project: demo
+#
+# STATUS DEFINITIONS:
+#   backlog -> drafted -> in-progress -> in-review -> done
+
+generated: 2025-01-01
+project: demo
+project_key: DEMO
+tracking_system: file-system
+story_location: "_bmad-output/stories"
+
+development_status:
+  # Epic 1: Foundations
+  epic-1: in-progress
+  1-1-setup-repo: done   # merged
+  1-2-ci: 'in-review'
+  1-3-docs: "backlog"
+  epic-1-retrospective: optional
+
+  # Epic 2
+  epic-2: backlog
+  2-1-auth: backlog
+`
+
+func TestUpdateStatus(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	tests := []struct {
+		name    string
+		input   string
+		key     string
+		value   State
+		replace [][2]string // old -> new line substitutions expected in the output
+		wantErr string
+	}{
+		{
+			name:  "plain value keeps trailing comment",
+			input: sprintStatusFixture,
+			key:   "1-1-setup-repo",
+			value: "in-review",
+			replace: [][2]string{
+				{"\ngenerated: 2025-01-01\n", "\ngenerated: 2026-03-04\n"},
+				{"1-1-setup-repo: done   # merged", "1-1-setup-repo: in-review   # merged"},
+			},
+		},
+		{
+			name:  "single-quoted value stays single-quoted",
+			input: sprintStatusFixture,
+			key:   "1-2-ci",
+			value: "done",
+			replace: [][2]string{
+				{"\ngenerated: 2025-01-01\n", "\ngenerated: 2026-03-04\n"},
+				{"1-2-ci: 'in-review'", "1-2-ci: 'done'"},
+			},
+		},
+		{
+			name:  "double-quoted value stays double-quoted",
+			input: sprintStatusFixture,
+			key:   "1-3-docs",
+			value: "drafted",
+			replace: [][2]string{
+				{"\ngenerated: 2025-01-01\n", "\ngenerated: 2026-03-04\n"},
+				{`1-3-docs: "backlog"`, `1-3-docs: "drafted"`},
+			},
+		},
+		{
+			name:  "last entry without trailing newline",
+			input: strings.TrimSuffix(sprintStatusFixture, "\n"),
+			key:   "2-1-auth",
+			value: "drafted",
+			replace: [][2]string{
+				{"\ngenerated: 2025-01-01\n", "\ngenerated: 2026-03-04\n"},
+				{"2-1-auth: backlog", "2-1-auth: drafted"},
+			},
+		},
+		{
+			name:  "generated keeps its layout",
+			input: "generated: \"2025-01-01 10:00\"\ndevelopment_status:\n  epic-1: backlog\n",
+			key:   "epic-1",
+			value: "in-progress",
+			replace: [][2]string{
+				{`generated: "2025-01-01 10:00"`, `generated: "2026-03-04 05:06"`},
+				{"epic-1: backlog", "epic-1: in-progress"},
+			},
+		},
+		{
+			name:    "no generated field",
+			input:   "development_status:\n  epic-1: backlog\n",
+			key:     "epic-1",
+			value:   "done",
+			replace: [][2]string{{"epic-1: backlog", "epic-1: done"}},
+		},
+		{
+			name:    "flow mapping",
+			input:   "development_status: {epic-1: backlog, 1-1-a: backlog}\n",
+			key:     "epic-1",
+			value:   "done",
+			replace: [][2]string{{"{epic-1: backlog,", "{epic-1: done,"}},
+		},
+		{
+			name:    "empty value",
+			input:   "development_status:\n  epic-1: backlog\n  1-1-a:\n  1-2-b: backlog\n",
+			key:     "1-1-a",
+			value:   "drafted",
+			replace: [][2]string{{"1-1-a:\n", "1-1-a: drafted\n"}},
+		},
+		{
+			name:    "value that needs quoting",
+			input:   "development_status:\n  epic-1: backlog\n",
+			key:     "epic-1",
+			value:   "true",
+			replace: [][2]string{{"epic-1: backlog", `epic-1: "true"`}},
+		},
+		{
+			name:    "unchanged value is a no-op",
+			input:   sprintStatusFixture,
+			key:     "2-1-auth",
+			value:   "backlog",
+			replace: nil,
+		},
+		{
+			name:    "unknown key",
+			input:   sprintStatusFixture,
+			key:     "9-9-nope",
+			value:   "done",
+			wantErr: `no entry "9-9-nope"`,
+		},
+		{
+			name:    "missing development_status",
+			input:   "generated: 2025-01-01\n",
+			key:     "epic-1",
+			value:   "done",
+			wantErr: "development_status key not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := UpdateStatus([]byte(tt.input), tt.key, tt.value, now)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("UpdateStatus error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateStatus: %v", err)
+			}
+			want := tt.input
+			for _, r := range tt.replace {
+				if !strings.Contains(want, r[0]) {
+					t.Fatalf("fixture does not contain %q", r[0])
+				}
+				want = strings.Replace(want, r[0], r[1], 1)
+			}
+			if string(got) != want {
+				t.Errorf("UpdateStatus output:\n%s\nwant:\n%s", got, want)
+			}
+			s, err := ParseBytes(got)
+			if err != nil {
+				t.Fatalf("output does not parse: %v", err)
+			}
+			if s.DevStatus[tt.key] != tt.value {
+				t.Errorf("development_status[%q] = %q after update, want %q", tt.key, s.DevStatus[tt.key], tt.value)
+			}
+		})
+	}
+}
+
+func TestSetStatus(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sprint-status.yaml")
+	if err := os.WriteFile(path, []byte(sprintStatusFixture), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetStatus(path, "2-1-auth", "drafted"); err != nil {
+		t.Fatalf("SetStatus: %v", err)
+	}
+	s, err := Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if s.DevStatus["2-1-auth"] != "drafted" {
+		t.Errorf("2-1-auth = %q, want drafted", s.DevStatus["2-1-auth"])
+	}
+	if s.Generated == "2025-01-01" {
+		t.Errorf("generated was not updated")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory has %d entries after write, want only the status file", len(entries))
+	}
+
+	if err := SetStatus(path, "missing", "done"); err == nil {
+		t.Errorf("SetStatus for a missing key should fail")
+	}
+}
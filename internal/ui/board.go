@@ -0,0 +1,222 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"atomicgo.dev/cursor"
+	"github.com/mattn/go-runewidth"
+	"github.com/pterm/pterm"
+)
+
+// boardInterval is how often a live StoryBoard redraws its rows.
+const boardInterval = 200 * time.Millisecond
+
+// boardKeyWidth is the display width of the story column.
+const boardKeyWidth = 28
+
+// StoryBoard shows the stories run auto --parallel is working on, one row each:
+// a spinner, the story, its phase, how long the phase has run and the agent's
+// latest output line. Live, the rows are redrawn in place below the regular
+// output, and pterm's printers write through the board so their messages appear
+// above the rows. Otherwise each phase start is printed as a line.
+type StoryBoard struct {
+	mu      sync.Mutex
+	live    bool
+	area    cursor.Area
+	rows    []*boardRow
+	frame   int
+	partial []byte // output not yet ended by a newline
+
+	restore func() // puts pterm's writers back
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+type boardRow struct {
+	story string
+	phase string
+	start time.Time
+	line  string
+}
+
+// NewStoryBoard starts a board. With live it redraws its rows in place until
+// Stop, so nothing else may write to stdout except through pterm.
+func NewStoryBoard(live bool) *StoryBoard {
+	b := &StoryBoard{live: live}
+	if !live {
+		return b
+	}
+	b.area = cursor.NewArea()
+	b.restore = redirectPterm(b)
+	b.stop, b.done = make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(b.done)
+		ticker := time.NewTicker(boardInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-b.stop:
+				return
+			case <-ticker.C:
+				b.mu.Lock()
+				b.frame++
+				b.draw()
+				b.mu.Unlock()
+			}
+		}
+	}()
+	return b
+}
+
+// redirectPterm sends the output of pterm's printers to w and returns a function
+// that restores it.
+func redirectPterm(w io.Writer) func() {
+	printers := []*pterm.PrefixPrinter{&pterm.Info, &pterm.Success, &pterm.Warning, &pterm.Error, &pterm.Description}
+	saved := make([]io.Writer, len(printers))
+	for i, p := range printers {
+		saved[i], p.Writer = p.Writer, w
+	}
+	section := pterm.DefaultSection.Writer
+	pterm.DefaultSection.Writer = w
+	pterm.SetDefaultOutput(w)
+	return func() {
+		for i, p := range printers {
+			p.Writer = saved[i]
+		}
+		pterm.DefaultSection.Writer = section
+		pterm.SetDefaultOutput(os.Stdout)
+	}
+}
+
+// SetPhase shows story as running phase, adding its row if needed.
+func (b *StoryBoard) SetPhase(story, phase string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	row := b.row(story)
+	if row == nil {
+		row = &boardRow{story: story}
+		b.rows = append(b.rows, row)
+	}
+	row.phase, row.start, row.line = phase, time.Now(), ""
+	if !b.live {
+		pterm.Info.Printf("%s: %s\n", story, phase)
+	}
+}
+
+// Output sets the latest output lines of story's phase; the last one is shown.
+func (b *StoryBoard) Output(story string, lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if row := b.row(story); row != nil {
+		row.line = lines[len(lines)-1]
+	}
+}
+
+// Writer returns a writer for story's raw agent output, which a board that is not
+// live prints line by line, prefixed with the story. A live board drops it: its
+// rows show the latest line passed to Output. Each Write must hold whole lines.
+func (b *StoryBoard) Writer(story string) io.Writer {
+	return storyWriter{board: b, prefix: pterm.Cyan("[" + story + "] ")}
+}
+
+type storyWriter struct {
+	board  *StoryBoard
+	prefix string
+}
+
+func (w storyWriter) Write(p []byte) (int, error) {
+	if w.board.live {
+		return len(p), nil
+	}
+	var sb strings.Builder
+	for _, line := range strings.Split(strings.TrimSuffix(string(p), "\n"), "\n") {
+		sb.WriteString(w.prefix + line + "\n")
+	}
+	w.board.mu.Lock()
+	defer w.board.mu.Unlock()
+	if _, err := io.WriteString(os.Stdout, sb.String()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Remove drops story's row.
+func (b *StoryBoard) Remove(story string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, row := range b.rows {
+		if row.story == story {
+			b.rows = append(b.rows[:i], b.rows[i+1:]...)
+			break
+		}
+	}
+	if b.live {
+		b.draw()
+	}
+}
+
+// Write prints complete lines of p above the rows. It lets pterm print while
+// the board is live.
+func (b *StoryBoard) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.partial = append(b.partial, p...)
+	end := strings.LastIndexByte(string(b.partial), '\n')
+	if end < 0 {
+		return len(p), nil
+	}
+	b.area.Clear()
+	_, err := os.Stdout.Write(b.partial[:end+1])
+	b.partial = append(b.partial[:0], b.partial[end+1:]...)
+	b.area = cursor.NewArea()
+	b.draw()
+	return len(p), err
+}
+
+// Stop clears the rows and gives pterm its writers back.
+func (b *StoryBoard) Stop() {
+	if !b.live {
+		return
+	}
+	close(b.stop)
+	<-b.done
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.area.Clear()
+	if len(b.partial) > 0 {
+		os.Stdout.Write(append(b.partial, '\n'))
+		b.partial = nil
+	}
+	b.restore()
+}
+
+func (b *StoryBoard) row(story string) *boardRow {
+	for _, row := range b.rows {
+		if row.story == story {
+			return row
+		}
+	}
+	return nil
+}
+
+// draw redraws the rows; the caller holds b.mu.
+func (b *StoryBoard) draw() {
+	var sb strings.Builder
+	for i, row := range b.rows {
+		spin := brailleChars[mod(b.frame+i, len(brailleChars))]
+		key := runewidth.FillRight(runewidth.Truncate(row.story, boardKeyWidth, "…"), boardKeyWidth)
+		elapsed := time.Since(row.start).Round(time.Second)
+		fmt.Fprintf(&sb, "  %s %s %s %s  %s\n",
+			pterm.Cyan(string(spin)), key, pterm.Cyan(fmt.Sprintf("%-14s", row.phase)),
+			pterm.Gray(fmt.Sprintf("%7s", elapsed)), FormatLastLineForStatus(row.line))
+	}
+	b.area.Update(sb.String())
+}
@@ -7,6 +7,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/MBFrosty/BMAD-Runner/internal/status"
+
 	"atomicgo.dev/cursor"
 	"github.com/mattn/go-runewidth"
 	"github.com/pterm/pterm"
@@ -276,6 +278,20 @@ func PrintEpicProgress(epicKey string, storiesDone, storiesTotal int) {
 	pterm.Println()
 }
 
+// progressBarWidth is the number of cells in a ProgressBar.
+const progressBarWidth = 10
+
+// ProgressBar returns a small text progress bar such as "███░░░░░░░ 3/10" for
+// the status command, or "" when total is 0.
+func ProgressBar(done, total int) string {
+	if total <= 0 {
+		return ""
+	}
+	filled := done * progressBarWidth / total
+	bar := pterm.Green(strings.Repeat("█", filled)) + pterm.Gray(strings.Repeat("░", progressBarWidth-filled))
+	return fmt.Sprintf("%s %d/%d", bar, done, total)
+}
+
 // PrintEpicPlanningBanner prints a banner before a single-epic automated planning run.
 // nextEpicNum is the epic number being planned; sessionCap is the max for this session.
 func PrintEpicPlanningBanner(primeDirectivePath string, nextEpicNum, sessionCap int) {
@@ -340,17 +356,38 @@ func PrintWorkPlan(w WorkPlan) {
 }
 
 // StatusIcon returns a styled status string with icon prefix for the status command.
-func StatusIcon(status string) string {
-	switch status {
-	case "done":
-		return pterm.Green("✔ " + status)
-	case "in-progress":
-		return pterm.Cyan("▶ " + status)
-	case "backlog":
-		return pterm.Gray("○ " + status)
-	case "deferred":
-		return pterm.Yellow("⚑ " + status)
+func StatusIcon(state status.State) string {
+	s := string(state)
+	switch state {
+	case status.StateDone, status.StateCompleted:
+		return pterm.Green("✔ " + s)
+	case status.StateInProgress:
+		return pterm.Cyan("▶ " + s)
+	case status.StateInReview, status.StateReview:
+		return pterm.Magenta("◎ " + s)
+	case status.StateDrafted, status.StateReadyForDev, status.StateContexted:
+		return pterm.Blue("◔ " + s)
+	case status.StateBacklog, status.StateOptional:
+		return pterm.Gray("○ " + s)
+	case status.StateDeferred:
+		return pterm.Yellow("⚑ " + s)
+	default:
+		return s
+	}
+}
+
+// ResultIcon returns a styled phase result with icon prefix for the logs command.
+func ResultIcon(result string) string {
+	switch result {
+	case "success":
+		return pterm.Green("✔ " + result)
+	case "running":
+		return pterm.Cyan("▶ " + result)
+	case "failed":
+		return pterm.Red("✖ " + result)
+	case "timeout", "interrupted":
+		return pterm.Yellow("⚑ " + result)
 	default:
-		return status
+		return result
 	}
 }